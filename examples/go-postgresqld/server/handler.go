@@ -19,7 +19,9 @@ import (
 
 	"github.com/cybergarage/go-logger/log"
 	"github.com/cybergarage/go-postgresql/postgresql"
+	"github.com/cybergarage/go-postgresql/postgresql/errors"
 	"github.com/cybergarage/go-postgresql/postgresql/protocol"
+	sqlerrors "github.com/cybergarage/go-sqlparser/sql/errors"
 )
 
 // ParserError handles a parser error.
@@ -29,7 +31,7 @@ func (server *Server) ParserError(conn postgresql.Conn, q string, err error) (pr
 		return postgresql.NewGetPartitionResponseForPgbench()
 	}
 
-	resErr := fmt.Errorf("parser error : %w", err)
+	resErr := errors.NewSQLErrorWith(sqlerrors.SyntaxError, fmt.Errorf("parser error : %w", err))
 	log.Warn(err.Error())
 	res, err := protocol.NewErrorResponseWith(resErr)
 	if err != nil {
@@ -18,6 +18,8 @@ import (
 	"fmt"
 
 	"github.com/cybergarage/go-logger/log"
+	pgErrors "github.com/cybergarage/go-postgresql/postgresql/errors"
+	"github.com/cybergarage/go-postgresql/postgresql/protocol"
 	pgSystem "github.com/cybergarage/go-postgresql/postgresql/system"
 	pgSystemFn "github.com/cybergarage/go-postgresql/postgresql/system/fn"
 	"github.com/cybergarage/go-sqlparser/sql"
@@ -55,8 +57,23 @@ func (store *Store) LookupDatabaseTable(conn net.Conn, dbName string, tblName st
 	return db, tbl, nil
 }
 
+// TableSchema returns the schema of the specified table in the database of the connection.
+func (store *Store) TableSchema(conn net.Conn, tblName string) (query.Schema, error) {
+	_, tbl, err := store.LookupDatabaseTable(conn, conn.Database(), tblName)
+	if err != nil {
+		return nil, err
+	}
+	return tbl.Schema, nil
+}
+
 // Begin should handle a BEGIN statement.
 func (store *Store) Begin(conn net.Conn, stmt query.Begin) error {
+	// The transaction characteristics such as the isolation level are provided by the connection.
+	if conn, ok := conn.(protocol.Conn); ok {
+		opts := conn.TransactionOptions()
+		log.Debugf("%v (%s %s)", stmt, opts.IsolationLevel, opts.AccessMode)
+		return nil
+	}
 	log.Debugf("%v", stmt)
 	return nil
 }
@@ -73,6 +90,42 @@ func (store *Store) Rollback(conn net.Conn, stmt query.Rollback) error {
 	return nil
 }
 
+// Savepoint should handle a SAVEPOINT statement.
+func (store *Store) Savepoint(conn net.Conn, name string) error {
+	log.Debugf("SAVEPOINT %s", name)
+	return nil
+}
+
+// ReleaseSavepoint should handle a RELEASE SAVEPOINT statement.
+func (store *Store) ReleaseSavepoint(conn net.Conn, name string) error {
+	log.Debugf("RELEASE SAVEPOINT %s", name)
+	return nil
+}
+
+// RollbackToSavepoint should handle a ROLLBACK TO SAVEPOINT statement.
+func (store *Store) RollbackToSavepoint(conn net.Conn, name string) error {
+	log.Debugf("ROLLBACK TO SAVEPOINT %s", name)
+	return nil
+}
+
+// PrepareTransaction should handle a PREPARE TRANSACTION statement.
+func (store *Store) PrepareTransaction(conn net.Conn, gid string) error {
+	log.Debugf("PREPARE TRANSACTION '%s'", gid)
+	return nil
+}
+
+// CommitPrepared should handle a COMMIT PREPARED statement.
+func (store *Store) CommitPrepared(conn net.Conn, gid string) error {
+	log.Debugf("COMMIT PREPARED '%s'", gid)
+	return nil
+}
+
+// RollbackPrepared should handle a ROLLBACK PREPARED statement.
+func (store *Store) RollbackPrepared(conn net.Conn, gid string) error {
+	log.Debugf("ROLLBACK PREPARED '%s'", gid)
+	return nil
+}
+
 // Use should handle a USE statement.
 func (store *Store) Use(conn net.Conn, stmt query.Use) error {
 	log.Debugf("%v", stmt)
@@ -168,6 +221,14 @@ func (store *Store) DropTable(conn net.Conn, stmt query.DropTable) error {
 		table, ok := db.LookupTable(tableName)
 		if !ok {
 			if stmt.IfExists() {
+				if pgConn, ok := conn.(protocol.Conn); ok {
+					notice := pgErrors.NewNotice(
+						errors.SuccessfulCompletion,
+						fmt.Sprintf("table \"%s\" does not exist, skipping", tableName))
+					if err := pgConn.ResponseNotice(notice); err != nil {
+						return err
+					}
+				}
 				continue
 			}
 			return errors.NewErrTableNotExist(tableName)
@@ -334,6 +395,33 @@ func (store *Store) Select(conn net.Conn, stmt query.Select) (sql.ResultSet, err
 	)
 }
 
+// DescribeSelect returns the result set schema of the specified SELECT statement without executing it.
+func (store *Store) DescribeSelect(conn net.Conn, stmt query.Select) (resultset.Schema, error) {
+	from := stmt.From()
+	switch {
+	case len(from) == 0:
+		return nil, errors.NewErrNoTable(stmt.String())
+	case 1 < len(from):
+		return nil, errors.NewErrMultipleTableNotSupported(from.String())
+	}
+
+	_, tbl, err := store.LookupDatabaseTable(conn, conn.Database(), from[0].TableName())
+	if err != nil {
+		return nil, err
+	}
+
+	selectors := stmt.Selectors()
+	if selectors.IsAsterisk() {
+		selectors = tbl.Selectors()
+	}
+
+	return resultset.NewSchema(
+		resultset.WithSchemaDatabaseName(conn.Database()),
+		resultset.WithSchemaTableSchema(tbl.Schema),
+		resultset.WithSchemaSelectors(selectors),
+	), nil
+}
+
 func (store *Store) SystemSelectFunction(conn net.Conn, stmt query.Select) (sql.ResultSet, error) {
 	selectorExecutor := func(selector query.Selector) (any, error) {
 		switch {
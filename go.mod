@@ -6,6 +6,7 @@ require (
 	github.com/cybergarage/go-authenticator v1.0.5
 	github.com/cybergarage/go-logger v1.3.12
 	github.com/cybergarage/go-safecast v1.3.5
+	github.com/cybergarage/go-sasl v1.2.6
 	github.com/cybergarage/go-sqlparser v1.6.1-0.20251127121345-13c5a03ca55c
 	github.com/cybergarage/go-sqltest v1.6.2-0.20251103145359-271a22450d34
 	github.com/cybergarage/go-tracing v1.1.7
@@ -17,7 +18,6 @@ require (
 require (
 	filippo.io/edwards25519 v1.1.1 // indirect
 	github.com/antlr4-go/antlr/v4 v4.13.1 // indirect
-	github.com/cybergarage/go-sasl v1.2.6 // indirect
 	github.com/go-sql-driver/mysql v1.9.0 // indirect
 	github.com/jackc/pgpassfile v1.0.0 // indirect
 	github.com/jackc/pgservicefile v0.0.0-20240606120523-5a60cdf6a761 // indirect
@@ -16,6 +16,71 @@ package auth
 
 import (
 	"errors"
+	"fmt"
 )
 
+// ErrAuthrizationFailed is returned when the authorization is failed.
 var ErrAuthrizationFailed = errors.New("authorization failed")
+
+// ErrInvalid is returned when the authentication data is invalid.
+var ErrInvalid = errors.New("invalid")
+
+// ErrNotSupported is returned when the authentication method is not supported.
+var ErrNotSupported = errors.New("not supported")
+
+func newErrInvalidSCRAMVerifier() error {
+	return fmt.Errorf("SCRAM verifier is %w", ErrInvalid)
+}
+
+func newErrInvalidSCRAMMessage(msg string) error {
+	return fmt.Errorf("SCRAM message (%s) is %w", msg, ErrInvalid)
+}
+
+// NewErrAuthrizationFailed returns a new authorization failed error for the specified user.
+func NewErrAuthrizationFailed(user string) error {
+	return fmt.Errorf("%w for user \"%s\"", ErrAuthrizationFailed, user)
+}
+
+func newErrChannelBindingNotSupported(v string) error {
+	return fmt.Errorf("%s channel binding for %s is %w", TLSServerEndPoint, v, ErrNotSupported)
+}
+
+// ErrNoHBAEntry is returned when no host-based authentication rule matches the connection.
+var ErrNoHBAEntry = errors.New("no pg_hba.conf entry")
+
+func newErrInvalidHBARule(line int, s string) error {
+	return fmt.Errorf("pg_hba.conf line %d (%s) is %w", line, s, ErrInvalid)
+}
+
+func newErrHBAKeywordNotSupported(line int, s string) error {
+	return fmt.Errorf("pg_hba.conf line %d (%s) is %w", line, s, ErrNotSupported)
+}
+
+// ErrHBAReject is returned when the matched host-based authentication rule rejects the connection.
+var ErrHBAReject = errors.New("pg_hba.conf rejects connection")
+
+// NewErrNoHBAEntry returns a new error for the connection attempt which matches no host-based authentication rule.
+func NewErrNoHBAEntry(q *HBAQuery) error {
+	ssl := "off"
+	if q.TLS {
+		ssl = "on"
+	}
+	return fmt.Errorf("%w for host \"%s\", user \"%s\", database \"%s\", SSL %s", ErrNoHBAEntry, q.Addr, q.User, q.Database, ssl)
+}
+
+// NewErrHBAReject returns a new error for the connection attempt which is rejected by a host-based authentication rule.
+func NewErrHBAReject(q *HBAQuery) error {
+	ssl := "off"
+	if q.TLS {
+		ssl = "on"
+	}
+	return fmt.Errorf("%w for host \"%s\", user \"%s\", database \"%s\", SSL %s", ErrHBAReject, q.Addr, q.User, q.Database, ssl)
+}
+
+func newErrInvalidIdentEntry(line int, s string) error {
+	return fmt.Errorf("pg_ident.conf line %d (%s) is %w", line, s, ErrInvalid)
+}
+
+func newErrIdentKeywordNotSupported(line int, s string) error {
+	return fmt.Errorf("pg_ident.conf line %d (%s) is %w", line, s, ErrNotSupported)
+}
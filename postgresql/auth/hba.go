@@ -0,0 +1,427 @@
+// Copyright (C) 2019 The go-postgresql Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// PostgreSQL: Documentation: 17: 20.1. The pg_hba.conf File
+// https://www.postgresql.org/docs/current/auth-pg-hba-conf.html
+
+// HBAConnType represents a connection type of a host-based authentication rule.
+type HBAConnType string
+
+const (
+	// HBALocal matches connection attempts using Unix-domain sockets.
+	HBALocal HBAConnType = "local"
+	// HBAHost matches connection attempts made using TCP/IP.
+	HBAHost HBAConnType = "host"
+	// HBAHostSSL matches connection attempts made using TCP/IP with SSL encryption.
+	HBAHostSSL HBAConnType = "hostssl"
+	// HBAHostNoSSL matches connection attempts made using TCP/IP without SSL encryption.
+	HBAHostNoSSL HBAConnType = "hostnossl"
+	// HBAHostGSSEnc matches connection attempts made using TCP/IP with GSSAPI encryption.
+	HBAHostGSSEnc HBAConnType = "hostgssenc"
+	// HBAHostNoGSSEnc matches connection attempts made using TCP/IP without GSSAPI encryption.
+	HBAHostNoGSSEnc HBAConnType = "hostnogssenc"
+)
+
+// HBAMapOption is the authentication option name of the user name map.
+const HBAMapOption = "map"
+
+const (
+	hbaAll      = "all"
+	hbaSameUser = "sameuser"
+)
+
+// hbaToken represents a database or user token of a host-based authentication rule.
+// Quoted tokens are never interpreted as keywords.
+type hbaToken struct {
+	value  string
+	quoted bool
+}
+
+func (token hbaToken) isKeyword(keyword string) bool {
+	return !token.quoted && token.value == keyword
+}
+
+// HBAQuery represents a connection attempt to evaluate host-based authentication rules.
+type HBAQuery struct {
+	// Local is true if the connection uses a Unix-domain socket.
+	Local bool
+	// TLS is true if the connection uses SSL encryption.
+	TLS bool
+	// Addr is the client IP address of a TCP/IP connection.
+	Addr net.IP
+	// Database is the requested database name.
+	Database string
+	// User is the requested user name.
+	User string
+}
+
+// HBARule represents a host-based authentication rule.
+type HBARule struct {
+	line      int
+	connType  HBAConnType
+	databases []hbaToken
+	users     []hbaToken
+	network   *net.IPNet
+	method    Method
+	options   map[string]string
+}
+
+// Line returns the line number of the rule.
+func (rule *HBARule) Line() int {
+	return rule.line
+}
+
+// ConnType returns the connection type of the rule.
+func (rule *HBARule) ConnType() HBAConnType {
+	return rule.connType
+}
+
+// Method returns the authentication method of the rule.
+func (rule *HBARule) Method() Method {
+	return rule.method
+}
+
+// Option returns the authentication option value of the specified name.
+func (rule *HBARule) Option(name string) (string, bool) {
+	v, ok := rule.options[name]
+	return v, ok
+}
+
+// Matches returns true if the rule matches the specified connection attempt.
+func (rule *HBARule) Matches(q *HBAQuery) bool {
+	switch rule.connType {
+	case HBALocal:
+		if !q.Local {
+			return false
+		}
+	case HBAHost, HBAHostNoGSSEnc:
+		if q.Local {
+			return false
+		}
+	case HBAHostSSL:
+		if q.Local || !q.TLS {
+			return false
+		}
+	case HBAHostNoSSL:
+		if q.Local || q.TLS {
+			return false
+		}
+	case HBAHostGSSEnc:
+		return false
+	}
+
+	if !q.Local && rule.network != nil {
+		if q.Addr == nil || !rule.network.Contains(q.Addr) {
+			return false
+		}
+	}
+
+	matchesDatabase := false
+	for _, token := range rule.databases {
+		if token.isKeyword(hbaAll) ||
+			(token.isKeyword(hbaSameUser) && q.Database == q.User) ||
+			token.value == q.Database {
+			matchesDatabase = true
+			break
+		}
+	}
+	if !matchesDatabase {
+		return false
+	}
+
+	for _, token := range rule.users {
+		if token.isKeyword(hbaAll) || token.value == q.User {
+			return true
+		}
+	}
+	return false
+}
+
+// HBA represents host-based authentication rules like pg_hba.conf.
+type HBA struct {
+	rules []*HBARule
+}
+
+// NewHBA returns new host-based authentication rules.
+func NewHBA(rules ...*HBARule) *HBA {
+	return &HBA{
+		rules: rules,
+	}
+}
+
+// NewHBAFromFile returns new host-based authentication rules from the specified pg_hba.conf file.
+func NewHBAFromFile(name string) (*HBA, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return NewHBAFromReader(f)
+}
+
+// NewHBAFromString returns new host-based authentication rules from the specified pg_hba.conf formatted string.
+func NewHBAFromString(s string) (*HBA, error) {
+	return NewHBAFromReader(strings.NewReader(s))
+}
+
+// NewHBAFromReader returns new host-based authentication rules from the specified pg_hba.conf formatted reader.
+func NewHBAFromReader(r io.Reader) (*HBA, error) {
+	hba := NewHBA()
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	ruleLineNo := 0
+	ruleLine := ""
+	for scanner.Scan() {
+		lineNo++
+		line := scanner.Text()
+		if ruleLine == "" {
+			ruleLineNo = lineNo
+		}
+		// A record can be continued onto the next line by ending the line with a backslash.
+		if strings.HasSuffix(line, "\\") {
+			ruleLine += strings.TrimSuffix(line, "\\") + " "
+			continue
+		}
+		ruleLine += line
+		rule, ok, err := newHBARuleFromLine(ruleLineNo, ruleLine)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			hba.rules = append(hba.rules, rule)
+		}
+		ruleLine = ""
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if ruleLine != "" {
+		rule, ok, err := newHBARuleFromLine(ruleLineNo, ruleLine)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			hba.rules = append(hba.rules, rule)
+		}
+	}
+	return hba, nil
+}
+
+// Rules returns the rules.
+func (hba *HBA) Rules() []*HBARule {
+	return hba.rules
+}
+
+// Lookup returns the first rule which matches the specified connection attempt.
+func (hba *HBA) Lookup(q *HBAQuery) (*HBARule, bool) {
+	for _, rule := range hba.rules {
+		if rule.Matches(q) {
+			return rule, true
+		}
+	}
+	return nil, false
+}
+
+// splitHBAFields splits the specified line into fields of comma separated tokens.
+// It returns false if the line has an unterminated quote.
+func splitHBAFields(line string) ([][]hbaToken, bool) {
+	fields := [][]hbaToken{}
+	tokens := []hbaToken{}
+	token := hbaToken{value: "", quoted: false}
+	hasToken := false
+	inQuote := false
+	continuesList := false
+
+	appendToken := func() {
+		if hasToken {
+			tokens = append(tokens, token)
+		}
+		token = hbaToken{value: "", quoted: false}
+		hasToken = false
+	}
+	appendField := func() {
+		appendToken()
+		if 0 < len(tokens) {
+			fields = append(fields, tokens)
+		}
+		tokens = []hbaToken{}
+	}
+
+	for n := 0; n < len(line); n++ {
+		c := line[n]
+		switch {
+		case inQuote:
+			if c == '"' {
+				inQuote = false
+			} else {
+				token.value += string(c)
+			}
+		case c == '"':
+			inQuote = true
+			token.quoted = true
+			hasToken = true
+			continuesList = false
+		case c == '#':
+			n = len(line)
+		case c == ',':
+			appendToken()
+			continuesList = true
+		case c == ' ' || c == '\t':
+			if !continuesList {
+				appendField()
+			}
+		default:
+			token.value += string(c)
+			hasToken = true
+			continuesList = false
+		}
+	}
+	if inQuote {
+		return nil, false
+	}
+	appendField()
+	return fields, true
+}
+
+// newHBARuleFromLine returns a new rule from the specified line, or false if the line has no rule.
+func newHBARuleFromLine(lineNo int, line string) (*HBARule, bool, error) {
+	fields, ok := splitHBAFields(line)
+	if !ok {
+		return nil, false, newErrInvalidHBARule(lineNo, line)
+	}
+	if len(fields) == 0 {
+		return nil, false, nil
+	}
+
+	fieldValue := func(n int) (string, error) {
+		if len(fields) <= n || len(fields[n]) != 1 {
+			return "", newErrInvalidHBARule(lineNo, line)
+		}
+		return fields[n][0].value, nil
+	}
+
+	rule := &HBARule{
+		line:      lineNo,
+		connType:  "",
+		databases: nil,
+		users:     nil,
+		network:   nil,
+		method:    "",
+		options:   map[string]string{},
+	}
+
+	connType, err := fieldValue(0)
+	if err != nil {
+		return nil, false, err
+	}
+	rule.connType = HBAConnType(connType)
+	switch rule.connType {
+	case HBALocal, HBAHost, HBAHostSSL, HBAHostNoSSL, HBAHostGSSEnc, HBAHostNoGSSEnc:
+	default:
+		return nil, false, newErrInvalidHBARule(lineNo, line)
+	}
+
+	if len(fields) < 4 {
+		return nil, false, newErrInvalidHBARule(lineNo, line)
+	}
+	rule.databases = fields[1]
+	rule.users = fields[2]
+	for _, token := range append(rule.databases, rule.users...) {
+		if !token.quoted && (strings.HasPrefix(token.value, "@") || strings.HasPrefix(token.value, "+") ||
+			token.value == "samerole" || token.value == "samegroup" || token.value == "replication") {
+			return nil, false, newErrHBAKeywordNotSupported(lineNo, token.value)
+		}
+	}
+
+	nextField := 3
+	if rule.connType != HBALocal {
+		addr, err := fieldValue(nextField)
+		if err != nil {
+			return nil, false, err
+		}
+		nextField++
+		switch {
+		case addr == hbaAll:
+			rule.network = nil
+		case strings.Contains(addr, "/"):
+			_, rule.network, err = net.ParseCIDR(addr)
+			if err != nil {
+				return nil, false, newErrInvalidHBARule(lineNo, line)
+			}
+		default:
+			// IP-address and IP-mask in separate fields
+			ip := net.ParseIP(addr)
+			if ip == nil {
+				return nil, false, newErrHBAKeywordNotSupported(lineNo, addr)
+			}
+			maskStr, err := fieldValue(nextField)
+			if err != nil {
+				return nil, false, err
+			}
+			nextField++
+			maskIP := net.ParseIP(maskStr)
+			if maskIP == nil {
+				return nil, false, newErrInvalidHBARule(lineNo, line)
+			}
+			mask := net.IPMask(maskIP.To16())
+			if ip4 := ip.To4(); ip4 != nil {
+				ip = ip4
+				mask = net.IPMask(maskIP.To4())
+			}
+			if mask == nil {
+				return nil, false, newErrInvalidHBARule(lineNo, line)
+			}
+			rule.network = &net.IPNet{IP: ip.Mask(mask), Mask: mask}
+		}
+	}
+
+	method, err := fieldValue(nextField)
+	if err != nil {
+		return nil, false, err
+	}
+	rule.method = Method(method)
+	switch rule.method {
+	case MethodTrust, MethodReject, MethodPassword, MethodMD5, MethodSCRAMSHA256, MethodCert:
+	default:
+		return nil, false, newErrHBAKeywordNotSupported(lineNo, method)
+	}
+
+	for n := nextField + 1; n < len(fields); n++ {
+		for _, token := range fields[n] {
+			name, value, ok := strings.Cut(token.value, "=")
+			if !ok {
+				return nil, false, newErrInvalidHBARule(lineNo, line)
+			}
+			rule.options[name] = value
+		}
+	}
+
+	return rule, true, nil
+}
+
+// String returns the rule location.
+func (rule *HBARule) String() string {
+	return "pg_hba.conf line " + strconv.Itoa(rule.line)
+}
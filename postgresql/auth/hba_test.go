@@ -0,0 +1,154 @@
+// Copyright (C) 2019 The go-postgresql Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+)
+
+func TestHBAFromString(t *testing.T) {
+	hba, err := NewHBAFromString(`
+# TYPE  DATABASE        USER            ADDRESS                 METHOD
+
+local   all             all                                     trust
+host    "db one",db2    alice,"Bob"     192.168.0.0/16          scram-sha-256
+host    all             all             10.0.0.0 255.0.0.0 \
+                                                                md5
+hostssl all             all             ::1/128                 cert map=certmap clientcert=verify-full
+hostnossl "all"         "sameuser"      all                     password # comment
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"4 local trust map[]",
+		"5 host scram-sha-256 map[]",
+		"6 host md5 map[]",
+		"8 hostssl cert map[clientcert:verify-full map:certmap]",
+		"9 hostnossl password map[]",
+	}
+	rules := hba.Rules()
+	if len(rules) != len(expected) {
+		t.Fatalf("rules (%d) != %d", len(rules), len(expected))
+	}
+	for n, rule := range rules {
+		actual := fmt.Sprintf("%d %s %s %v", rule.Line(), rule.ConnType(), rule.Method(), rule.options)
+		if actual != expected[n] {
+			t.Errorf("%s != %s", actual, expected[n])
+		}
+	}
+}
+
+func TestHBAFromStringErrors(t *testing.T) {
+	tests := []struct {
+		line     string
+		expected error
+	}{
+		{`host "all all 127.0.0.1/32 trust`, ErrInvalid},
+		{"host all all 127.0.0.1/33 trust", ErrInvalid},
+		{"host all all 127.0.0.1 255.0.0.256 trust", ErrInvalid},
+		{"host all all 127.0.0.1", ErrInvalid},
+		{"local all", ErrInvalid},
+		{"remote all all all trust", ErrInvalid},
+		{"host all all all trust map", ErrInvalid},
+		{"host all,db1 all,user1 all ldap", ErrNotSupported},
+		{"host all all localhost trust", ErrNotSupported},
+		{"host @dbfile all all trust", ErrNotSupported},
+		{"host all @userfile all trust", ErrNotSupported},
+		{"host all +group all trust", ErrNotSupported},
+		{"host samerole all all trust", ErrNotSupported},
+		{"host samegroup all all trust", ErrNotSupported},
+		{"host replication all all trust", ErrNotSupported},
+	}
+
+	for _, test := range tests {
+		_, err := NewHBAFromString(test.line)
+		if !errors.Is(err, test.expected) {
+			t.Errorf("%s: %v (expected %s)", test.line, err, test.expected)
+		}
+	}
+
+	// Quoted tokens are not keywords.
+
+	for _, line := range []string{`host "replication" "+group" all trust`, `host "@dbfile" "samerole" all trust`} {
+		_, err := NewHBAFromString(line)
+		if err != nil {
+			t.Errorf("%s: %s", line, err)
+		}
+	}
+}
+
+func TestHBALookup(t *testing.T) {
+	hba, err := NewHBAFromString(`
+local        all       all                  reject
+hostgssenc   all       all       all        trust
+hostssl      all       certuser  all        cert
+hostnossl    all       certuser  all        reject
+host         "all"     all       all        md5
+host         sameuser  all       127.0.0.1/32   trust
+host         db1,"db 2" alice,bob 192.168.1.0 255.255.255.0 password
+host         all       "all"     ::1/128    scram-sha-256
+hostnogssenc all       all       10.0.0.0/8 scram-sha-256
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		local    bool
+		tls      bool
+		addr     string
+		database string
+		user     string
+		expected int
+	}{
+		{true, false, "", "db1", "alice", 2},
+		{false, true, "127.0.0.1", "db1", "certuser", 4},
+		{false, false, "127.0.0.1", "db1", "certuser", 5},
+		{false, false, "127.0.0.1", "all", "alice", 6},
+		{false, false, "127.0.0.1", "alice", "alice", 7},
+		{false, false, "127.0.0.2", "alice", "alice", 0},
+		{false, false, "192.168.1.10", "db1", "alice", 8},
+		{false, true, "192.168.1.10", "db 2", "bob", 8},
+		{false, false, "192.168.2.10", "db1", "alice", 0},
+		{false, false, "192.168.1.10", "db3", "alice", 0},
+		{false, false, "192.168.1.10", "db1", "carol", 0},
+		{false, false, "::1", "db1", "alice", 0},
+		{false, false, "::1", "db1", "all", 9},
+		{false, false, "10.1.2.3", "db1", "alice", 10},
+		{false, true, "10.1.2.3", "db1", "alice", 10},
+	}
+
+	for _, test := range tests {
+		q := &HBAQuery{
+			Local:    test.local,
+			TLS:      test.tls,
+			Addr:     net.ParseIP(test.addr),
+			Database: test.database,
+			User:     test.user,
+		}
+		line := 0
+		if rule, ok := hba.Lookup(q); ok {
+			line = rule.Line()
+		}
+		if line != test.expected {
+			t.Errorf("%+v: line %d != %d", *q, line, test.expected)
+		}
+	}
+}
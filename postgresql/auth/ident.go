@@ -0,0 +1,213 @@
+// Copyright (C) 2019 The go-postgresql Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"bufio"
+	"crypto/x509"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// PostgreSQL: Documentation: 17: 20.2. User Name Maps
+// https://www.postgresql.org/docs/current/auth-username-maps.html
+
+// IdentEntry represents a user name map entry which maps a system user name,
+// such as a common name of a client certificate, to a database user name.
+type IdentEntry struct {
+	mapName    string
+	systemUser string
+	regexp     *regexp.Regexp
+	dbUser     hbaToken
+}
+
+// NewIdentEntry returns a new user name map entry.
+// The system user name is treated as a regular expression if it starts with a slash,
+// and the database user name can contain \1 to refer to the first captured subexpression.
+func NewIdentEntry(mapName string, systemUser string, dbUser string) (*IdentEntry, error) {
+	return newIdentEntryWith(mapName, systemUser, hbaToken{value: dbUser, quoted: false})
+}
+
+func newIdentEntryWith(mapName string, systemUser string, dbUser hbaToken) (*IdentEntry, error) {
+	entry := &IdentEntry{
+		mapName:    mapName,
+		systemUser: systemUser,
+		regexp:     nil,
+		dbUser:     dbUser,
+	}
+	if strings.HasPrefix(systemUser, "/") {
+		re, err := regexp.Compile(systemUser[1:])
+		if err != nil {
+			return nil, err
+		}
+		entry.regexp = re
+	}
+	return entry, nil
+}
+
+// MapName returns the map name of the entry.
+func (entry *IdentEntry) MapName() string {
+	return entry.mapName
+}
+
+// SystemUser returns the system user name or the regular expression of the entry.
+func (entry *IdentEntry) SystemUser() string {
+	return entry.systemUser
+}
+
+// DatabaseUser returns the database user name of the entry.
+func (entry *IdentEntry) DatabaseUser() string {
+	return entry.dbUser.value
+}
+
+// Matches returns true if the entry allows the system user to connect as the database user.
+func (entry *IdentEntry) Matches(systemUser string, dbUser string) bool {
+	if entry.regexp == nil {
+		if entry.systemUser != systemUser {
+			return false
+		}
+		return entry.dbUser.isKeyword(hbaAll) || entry.dbUser.value == dbUser
+	}
+
+	submatches := entry.regexp.FindStringSubmatch(systemUser)
+	if submatches == nil {
+		return false
+	}
+	if entry.dbUser.isKeyword(hbaAll) {
+		return true
+	}
+	mappedUser := entry.dbUser.value
+	for n := len(submatches) - 1; 1 <= n; n-- {
+		mappedUser = strings.ReplaceAll(mappedUser, "\\"+strconv.Itoa(n), submatches[n])
+	}
+	return mappedUser == dbUser
+}
+
+// DefaultIdentMapName is the map name which is used for the certificate authentication
+// if the host-based authentication rule has no map option or no rule is configured.
+const DefaultIdentMapName = "default"
+
+// IdentMap represents user name maps like pg_ident.conf.
+type IdentMap struct {
+	entries []*IdentEntry
+}
+
+// NewIdentMap returns new user name maps.
+func NewIdentMap(entries ...*IdentEntry) *IdentMap {
+	return &IdentMap{
+		entries: entries,
+	}
+}
+
+// NewIdentMapFromFile returns new user name maps from the specified pg_ident.conf file.
+func NewIdentMapFromFile(name string) (*IdentMap, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return NewIdentMapFromReader(f)
+}
+
+// NewIdentMapFromString returns new user name maps from the specified pg_ident.conf formatted string.
+func NewIdentMapFromString(s string) (*IdentMap, error) {
+	return NewIdentMapFromReader(strings.NewReader(s))
+}
+
+// NewIdentMapFromReader returns new user name maps from the specified pg_ident.conf formatted reader.
+func NewIdentMapFromReader(r io.Reader) (*IdentMap, error) {
+	identMap := NewIdentMap()
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := scanner.Text()
+		fields, ok := splitHBAFields(line)
+		if !ok {
+			return nil, newErrInvalidIdentEntry(lineNo, line)
+		}
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 3 {
+			return nil, newErrInvalidIdentEntry(lineNo, line)
+		}
+		for _, field := range fields {
+			if len(field) != 1 {
+				return nil, newErrInvalidIdentEntry(lineNo, line)
+			}
+		}
+		if !fields[2][0].quoted && strings.HasPrefix(fields[2][0].value, "+") {
+			return nil, newErrIdentKeywordNotSupported(lineNo, fields[2][0].value)
+		}
+		entry, err := newIdentEntryWith(fields[0][0].value, fields[1][0].value, fields[2][0])
+		if err != nil {
+			return nil, newErrInvalidIdentEntry(lineNo, line)
+		}
+		identMap.entries = append(identMap.entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return identMap, nil
+}
+
+// Entries returns the entries.
+func (identMap *IdentMap) Entries() []*IdentEntry {
+	return identMap.entries
+}
+
+// HasMap returns true if the maps have any entry of the specified map name.
+func (identMap *IdentMap) HasMap(mapName string) bool {
+	for _, entry := range identMap.entries {
+		if entry.mapName == mapName {
+			return true
+		}
+	}
+	return false
+}
+
+// Matches returns true if any of the system user names is allowed to connect as the database user in the specified map.
+func (identMap *IdentMap) Matches(mapName string, systemUsers []string, dbUser string) bool {
+	for _, entry := range identMap.entries {
+		if entry.mapName != mapName {
+			continue
+		}
+		for _, systemUser := range systemUsers {
+			if entry.Matches(systemUser, dbUser) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// NewCertificateIdentities returns the identities of the specified client certificate
+// which are the common name and the subject alternative names.
+func NewCertificateIdentities(cert *x509.Certificate) []string {
+	identities := []string{}
+	if 0 < len(cert.Subject.CommonName) {
+		identities = append(identities, cert.Subject.CommonName)
+	}
+	identities = append(identities, cert.DNSNames...)
+	identities = append(identities, cert.EmailAddresses...)
+	for _, uri := range cert.URIs {
+		identities = append(identities, uri.String())
+	}
+	return identities
+}
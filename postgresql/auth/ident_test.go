@@ -0,0 +1,167 @@
+// Copyright (C) 2019 The go-postgresql Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"net/url"
+	"testing"
+)
+
+func TestIdentMapFromString(t *testing.T) {
+	identMap, err := NewIdentMapFromString(`
+# MAPNAME   SYSTEM-USERNAME             PG-USERNAME
+
+certmap     client.example.com          alice
+certmap     /^(.*)@example\.com$        \1
+certmap     /^(.*)\.(.*)@example\.org$  \2_\1
+certmap     admin                       all
+othermap    "bob"                       "all"  # comment
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(identMap.Entries()) != 5 {
+		t.Fatalf("entries (%d) != %d", len(identMap.Entries()), 5)
+	}
+
+	tests := []struct {
+		mapName     string
+		systemUsers []string
+		dbUser      string
+		expected    bool
+	}{
+		{"certmap", []string{"client.example.com"}, "alice", true},
+		{"certmap", []string{"client.example.com"}, "bob", false},
+		{"certmap", []string{"carol@example.com"}, "carol", true},
+		{"certmap", []string{"carol@example.com"}, "dave", false},
+		{"certmap", []string{"carol@example.net"}, "carol", false},
+		{"certmap", []string{"carol.smith@example.org"}, "smith_carol", true},
+		{"certmap", []string{"carol.smith@example.org"}, "carol_smith", false},
+		{"certmap", []string{"admin"}, "alice", true},
+		{"certmap", []string{"admin"}, "bob", true},
+		{"certmap", []string{"unknown", "dave@example.com"}, "dave", true},
+		{"certmap", []string{}, "alice", false},
+		{"othermap", []string{"client.example.com"}, "alice", false},
+		{"othermap", []string{"bob"}, "all", true},
+		{"othermap", []string{"bob"}, "bob", false},
+		{"unknownmap", []string{"admin"}, "alice", false},
+	}
+
+	for _, test := range tests {
+		if identMap.Matches(test.mapName, test.systemUsers, test.dbUser) != test.expected {
+			t.Errorf("%s %v %s != %t", test.mapName, test.systemUsers, test.dbUser, test.expected)
+		}
+	}
+
+	for mapName, expected := range map[string]bool{"certmap": true, "othermap": true, DefaultIdentMapName: false} {
+		if identMap.HasMap(mapName) != expected {
+			t.Errorf("%s != %t", mapName, expected)
+		}
+	}
+}
+
+func TestIdentMapFromStringErrors(t *testing.T) {
+	tests := []struct {
+		line     string
+		expected error
+	}{
+		{"certmap client", ErrInvalid},
+		{"certmap client alice bob", ErrInvalid},
+		{"certmap client alice,bob", ErrInvalid},
+		{`certmap "client alice`, ErrInvalid},
+		{"certmap /^(.*$ alice", ErrInvalid},
+		{"certmap client +group", ErrNotSupported},
+	}
+
+	for _, test := range tests {
+		_, err := NewIdentMapFromString(test.line)
+		if !errors.Is(err, test.expected) {
+			t.Errorf("%s: %v (expected %s)", test.line, err, test.expected)
+		}
+	}
+}
+
+func TestIdentEntry(t *testing.T) {
+	entry, err := NewIdentEntry("certmap", `/^cn=(.*),ou=(.*)$`, `\2\1`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !entry.Matches("cn=alice,ou=dev", "devalice") {
+		t.Errorf("%s %s", entry.SystemUser(), entry.DatabaseUser())
+	}
+	if entry.Matches("cn=alice,ou=dev", "alicedev") {
+		t.Errorf("%s %s", entry.SystemUser(), entry.DatabaseUser())
+	}
+
+	entry, err = NewIdentEntry("certmap", "admin", "all")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !entry.Matches("admin", "alice") {
+		t.Errorf("%s %s", entry.SystemUser(), entry.DatabaseUser())
+	}
+
+	if _, err := NewIdentEntry("certmap", "/^(.*$", "alice"); err == nil {
+		t.Errorf("%s", "/^(.*$")
+	}
+}
+
+func TestCertificateIdentities(t *testing.T) {
+	uri, err := url.Parse("spiffe://example.com/alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert := &x509.Certificate{
+		Subject:        pkix.Name{CommonName: "client.example.com"},
+		DNSNames:       []string{"alice.example.com"},
+		EmailAddresses: []string{"alice@example.com"},
+		URIs:           []*url.URL{uri},
+	}
+
+	identMap, err := NewIdentMapFromString(`
+certmap  /^(.*)\.example\.com$   \1
+urimap   /^spiffe://example\.com/(.*)$  \1
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	identities := NewCertificateIdentities(cert)
+	expected := []string{"client.example.com", "alice.example.com", "alice@example.com", "spiffe://example.com/alice"}
+	if len(identities) != len(expected) {
+		t.Fatalf("%v != %v", identities, expected)
+	}
+	for n, identity := range identities {
+		if identity != expected[n] {
+			t.Errorf("%s != %s", identity, expected[n])
+		}
+	}
+
+	for _, dbUser := range []string{"client", "alice"} {
+		if !identMap.Matches("certmap", identities, dbUser) {
+			t.Errorf("certmap %v %s", identities, dbUser)
+		}
+	}
+	if !identMap.Matches("urimap", identities, "alice") {
+		t.Errorf("urimap %v %s", identities, "alice")
+	}
+	if identMap.Matches("certmap", identities, "bob") {
+		t.Errorf("certmap %v %s", identities, "bob")
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright (C) 2019 The go-postgresql Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"crypto/md5" // nolint:gosec
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"strings"
+)
+
+// PostgreSQL: Documentation: 17: 20.5. Password Authentication
+// https://www.postgresql.org/docs/current/auth-password.html
+
+const (
+	// MD5 is the mechanism name of the MD5 password authentication.
+	MD5 = "MD5"
+	// MD5PasswordPrefix is the prefix of MD5 hashed passwords.
+	MD5PasswordPrefix = "md5"
+	// MD5SaltLength is the salt length of the MD5 password authentication.
+	MD5SaltLength = 4
+)
+
+func md5Hex(s ...string) string {
+	h := md5.New() // nolint:gosec
+	for _, v := range s {
+		h.Write([]byte(v))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// NewMD5Password returns the MD5 hashed password which is compatible with the rolpassword column of PostgreSQL:
+// "md5" + md5(password + username).
+func NewMD5Password(username string, password string) string {
+	return MD5PasswordPrefix + md5Hex(password, username)
+}
+
+// IsMD5Password returns true if the specified string is a MD5 hashed password.
+func IsMD5Password(s string) bool {
+	if len(s) != len(MD5PasswordPrefix)+md5.Size*2 || !strings.HasPrefix(s, MD5PasswordPrefix) {
+		return false
+	}
+	_, err := hex.DecodeString(s[len(MD5PasswordPrefix):])
+	return err == nil
+}
+
+// NewMD5Salt returns a new random salt for the MD5 password authentication.
+func NewMD5Salt() ([]byte, error) {
+	salt := make([]byte, MD5SaltLength)
+	_, err := rand.Read(salt)
+	if err != nil {
+		return nil, err
+	}
+	return salt, nil
+}
+
+// NewMD5SaltedPassword returns the password which the client sends for the MD5 password authentication:
+// "md5" + md5(md5(password + username) + salt).
+func NewMD5SaltedPassword(md5Password string, salt []byte) string {
+	return MD5PasswordPrefix + md5Hex(strings.TrimPrefix(md5Password, MD5PasswordPrefix), string(salt))
+}
+
+// VerifyMD5Password returns true if the client password matches the stored password with the salt.
+// The stored password can be a MD5 hashed password or a plaintext password.
+func VerifyMD5Password(username string, storedPassword string, salt []byte, clientPassword string) bool {
+	md5Password := storedPassword
+	if !IsMD5Password(storedPassword) {
+		md5Password = NewMD5Password(username, storedPassword)
+	}
+	expected := NewMD5SaltedPassword(md5Password, salt)
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(clientPassword)) == 1
+}
@@ -0,0 +1,82 @@
+// Copyright (C) 2019 The go-postgresql Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"testing"
+)
+
+func TestMD5Password(t *testing.T) {
+	salt := []byte{0x01, 0x02, 0x03, 0x04}
+
+	tests := []struct {
+		username       string
+		password       string
+		md5Password    string
+		saltedPassword string
+	}{
+		{"postgres", "postgres", "md53175bce1d3201d16594cebf9d7eb3f9d", "md568be9ed08db75f318087ab337aaea044"},
+		{"md5user", "md5password", "md547096050c2d2a64ce5f1abb9b5af6236", "md53ff9232e213a8ebbe224b0e8d713453c"},
+		{"alice", "secret", "md54a0a68b43b6cd5cf266fa02f196e2371", "md598a0412b9c31436fc53776e863350083"},
+	}
+
+	for _, test := range tests {
+		md5Password := NewMD5Password(test.username, test.password)
+		if md5Password != test.md5Password {
+			t.Errorf("%s: %s != %s", test.username, md5Password, test.md5Password)
+		}
+		if !IsMD5Password(md5Password) {
+			t.Errorf("%s: %s is not a MD5 password", test.username, md5Password)
+		}
+		saltedPassword := NewMD5SaltedPassword(md5Password, salt)
+		if saltedPassword != test.saltedPassword {
+			t.Errorf("%s: %s != %s", test.username, saltedPassword, test.saltedPassword)
+		}
+	}
+
+	for _, s := range []string{"", "postgres", "md5", "md53175bce1d3201d16594cebf9d7eb3f9", "md53175bce1d3201d16594cebf9d7eb3f9g", "MD53175bce1d3201d16594cebf9d7eb3f9d"} {
+		if IsMD5Password(s) {
+			t.Errorf("%s is a MD5 password", s)
+		}
+	}
+}
+
+func TestVerifyMD5Password(t *testing.T) {
+	salt := []byte{0x01, 0x02, 0x03, 0x04}
+
+	tests := []struct {
+		username       string
+		storedPassword string
+		salt           []byte
+		clientPassword string
+		expected       bool
+	}{
+		{"postgres", "md53175bce1d3201d16594cebf9d7eb3f9d", salt, "md568be9ed08db75f318087ab337aaea044", true},
+		{"postgres", "postgres", salt, "md568be9ed08db75f318087ab337aaea044", true},
+		{"postgres", "md53175bce1d3201d16594cebf9d7eb3f9d", []byte{0x04, 0x03, 0x02, 0x01}, "md568be9ed08db75f318087ab337aaea044", false},
+		{"alice", "md53175bce1d3201d16594cebf9d7eb3f9d", salt, "md568be9ed08db75f318087ab337aaea044", true},
+		{"alice", "postgres", salt, "md568be9ed08db75f318087ab337aaea044", false},
+		{"postgres", "md53175bce1d3201d16594cebf9d7eb3f9d", salt, "md53175bce1d3201d16594cebf9d7eb3f9d", false},
+		{"postgres", "postgres", salt, "postgres", false},
+		{"postgres", "md53175bce1d3201d16594cebf9d7eb3f9d", salt, "", false},
+	}
+
+	for _, test := range tests {
+		ok := VerifyMD5Password(test.username, test.storedPassword, test.salt, test.clientPassword)
+		if ok != test.expected {
+			t.Errorf("%s %s %v %s: %t != %t", test.username, test.storedPassword, test.salt, test.clientPassword, ok, test.expected)
+		}
+	}
+}
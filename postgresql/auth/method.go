@@ -0,0 +1,41 @@
+// Copyright (C) 2019 The go-postgresql Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+// PostgreSQL: Documentation: 17: 20.3. Authentication Methods
+// https://www.postgresql.org/docs/current/auth-methods.html
+
+// Method represents a client authentication method.
+type Method string
+
+const (
+	// MethodTrust allows the connection unconditionally.
+	MethodTrust Method = "trust"
+	// MethodReject rejects the connection unconditionally.
+	MethodReject Method = "reject"
+	// MethodPassword requires the client to send a cleartext password.
+	MethodPassword Method = "password"
+	// MethodMD5 requires the client to send a MD5 hashed password with a random salt.
+	MethodMD5 Method = "md5"
+	// MethodSCRAMSHA256 requires the client to perform the SCRAM-SHA-256 SASL exchange.
+	MethodSCRAMSHA256 Method = "scram-sha-256"
+	// MethodCert requires the client to provide a valid TLS client certificate for the user.
+	MethodCert Method = "cert"
+)
+
+// String returns the method name.
+func (m Method) String() string {
+	return string(m)
+}
@@ -0,0 +1,69 @@
+// Copyright (C) 2019 The go-postgresql Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"crypto/subtle"
+)
+
+// LookupPassword looks up the stored password of the specified user from the credential store.
+// The returned password can be a plaintext password, a MD5 hashed password or a SCRAM verifier.
+func LookupPassword(store CredentialStore, username string, mech string) (string, bool, error) {
+	if store == nil {
+		return "", false, nil
+	}
+	q, err := NewQuery(
+		WithQueryUsername(username),
+		WithQueryMechanism(mech),
+	)
+	if err != nil {
+		return "", false, err
+	}
+	cred, ok, err := store.LookupCredential(q)
+	if err != nil || !ok {
+		return "", false, err
+	}
+	switch v := cred.Password().(type) {
+	case string:
+		return v, true, nil
+	case []byte:
+		return string(v), true, nil
+	case *SCRAMVerifier:
+		return v.String(), true, nil
+	}
+	return "", false, nil
+}
+
+// IsHashedPassword returns true if the specified stored password is a MD5 hashed password or a SCRAM verifier.
+func IsHashedPassword(storedPassword string) bool {
+	return IsMD5Password(storedPassword) || IsSCRAMVerifier(storedPassword)
+}
+
+// VerifyPassword returns true if the cleartext password matches the stored password.
+// The stored password can be a plaintext password, a MD5 hashed password or a SCRAM verifier.
+func VerifyPassword(username string, storedPassword string, password string) bool {
+	if IsMD5Password(storedPassword) {
+		md5Password := NewMD5Password(username, password)
+		return subtle.ConstantTimeCompare([]byte(md5Password), []byte(storedPassword)) == 1
+	}
+	if stored, err := NewSCRAMVerifierFromString(storedPassword); err == nil {
+		verifier, err := NewSCRAMVerifierWith(password, stored.Salt, stored.IterationCount)
+		if err != nil {
+			return false
+		}
+		return subtle.ConstantTimeCompare(verifier.StoredKey, stored.StoredKey) == 1
+	}
+	return subtle.ConstantTimeCompare([]byte(password), []byte(storedPassword)) == 1
+}
@@ -0,0 +1,53 @@
+// Copyright (C) 2019 The go-postgresql Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"hash"
+)
+
+// RFC 5929 - Channel Bindings for TLS
+// https://datatracker.ietf.org/doc/html/rfc5929
+
+const (
+	// SCRAMSHA256Plus is the SCRAM-SHA-256-PLUS mechanism name.
+	SCRAMSHA256Plus = "SCRAM-SHA-256-PLUS"
+	// TLSServerEndPoint is the tls-server-end-point channel binding type name.
+	TLSServerEndPoint = "tls-server-end-point"
+)
+
+// NewTLSServerEndPointWith returns the tls-server-end-point channel binding data of the specified server certificate.
+func NewTLSServerEndPointWith(cert *x509.Certificate) ([]byte, error) {
+	// RFC 5929: 4.1. The tls-server-end-point Channel Binding Type
+	// If the certificate's signatureAlgorithm uses a single hash function and that hash function is either MD5 or SHA-1,
+	// then use SHA-256. Otherwise, use the hash function of the signatureAlgorithm.
+	var h hash.Hash
+	switch cert.SignatureAlgorithm { // nolint:exhaustive
+	case x509.MD5WithRSA, x509.SHA1WithRSA, x509.ECDSAWithSHA1, x509.DSAWithSHA1,
+		x509.SHA256WithRSA, x509.SHA256WithRSAPSS, x509.ECDSAWithSHA256, x509.DSAWithSHA256:
+		h = sha256.New()
+	case x509.SHA384WithRSA, x509.SHA384WithRSAPSS, x509.ECDSAWithSHA384:
+		h = sha512.New384()
+	case x509.SHA512WithRSA, x509.SHA512WithRSAPSS, x509.ECDSAWithSHA512:
+		h = sha512.New()
+	default:
+		return nil, newErrChannelBindingNotSupported(cert.SignatureAlgorithm.String())
+	}
+	h.Write(cert.Raw)
+	return h.Sum(nil), nil
+}
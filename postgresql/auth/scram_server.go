@@ -0,0 +1,284 @@
+// Copyright (C) 2019 The go-postgresql Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"crypto/hmac"
+	"encoding/base64"
+	"strings"
+
+	"github.com/cybergarage/go-sasl/sasl/gss"
+	"github.com/cybergarage/go-sasl/sasl/scram"
+	"github.com/cybergarage/go-sasl/sasl/util/rand"
+)
+
+const (
+	scramNonceLength = 18
+)
+
+// SCRAMServer represents a server side SCRAM-SHA-256 exchange for a connection.
+type SCRAMServer struct {
+	credStore          CredentialStore
+	username           string
+	mechanism          string
+	cbData             []byte
+	mockNonce          []byte
+	verifier           *SCRAMVerifier
+	isMock             bool
+	gs2Header          string
+	clientFirstMsgBare string
+	serverFirstMsg     string
+	nonce              string
+}
+
+// SCRAMServerOption represents a SCRAM server option.
+type SCRAMServerOption func(*SCRAMServer)
+
+// WithSCRAMServerCredentialStore returns an option to set the credential store.
+func WithSCRAMServerCredentialStore(store CredentialStore) SCRAMServerOption {
+	return func(server *SCRAMServer) {
+		server.credStore = store
+	}
+}
+
+// WithSCRAMServerUsername returns an option to set the username.
+// PostgreSQL ignores the username in the SCRAM messages and uses the user of the startup message instead.
+func WithSCRAMServerUsername(username string) SCRAMServerOption {
+	return func(server *SCRAMServer) {
+		server.username = username
+	}
+}
+
+// WithSCRAMServerMechanism returns an option to set the mechanism selected by the client.
+func WithSCRAMServerMechanism(mech string) SCRAMServerOption {
+	return func(server *SCRAMServer) {
+		server.mechanism = mech
+	}
+}
+
+// WithSCRAMServerChannelBinding returns an option to set the tls-server-end-point channel binding data of the connection.
+// The server supports SCRAM-SHA-256-PLUS only if the channel binding data is set.
+func WithSCRAMServerChannelBinding(data []byte) SCRAMServerOption {
+	return func(server *SCRAMServer) {
+		server.cbData = data
+	}
+}
+
+// WithSCRAMServerMockNonce returns an option to set the server-wide nonce to derive the salts of mock verifiers.
+// The mock verifiers are used for the users who do not exist not to reveal whether the users exist.
+func WithSCRAMServerMockNonce(nonce []byte) SCRAMServerOption {
+	return func(server *SCRAMServer) {
+		server.mockNonce = nonce
+	}
+}
+
+// NewSCRAMServer returns a new SCRAM server with the specified options.
+func NewSCRAMServer(opts ...SCRAMServerOption) *SCRAMServer {
+	server := &SCRAMServer{
+		credStore:          nil,
+		username:           "",
+		mechanism:          SCRAMSHA256,
+		cbData:             nil,
+		mockNonce:          nil,
+		verifier:           nil,
+		isMock:             false,
+		gs2Header:          "",
+		clientFirstMsgBare: "",
+		serverFirstMsg:     "",
+		nonce:              "",
+	}
+	for _, opt := range opts {
+		opt(server)
+	}
+	return server
+}
+
+// Username returns the username.
+func (server *SCRAMServer) Username() string {
+	return server.username
+}
+
+// lookupVerifier looks up the SCRAM verifier of the user from the credential store.
+// The stored password can be a SCRAM verifier or a plaintext password, but not a MD5 hashed password.
+func (server *SCRAMServer) lookupVerifier() (*SCRAMVerifier, bool, error) {
+	password, ok, err := LookupPassword(server.credStore, server.username, server.mechanism)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	if IsMD5Password(password) {
+		return nil, false, nil
+	}
+	if verifier, err := NewSCRAMVerifierFromString(password); err == nil {
+		return verifier, true, nil
+	}
+	verifier, err := NewSCRAMVerifierFromPassword(password)
+	if err != nil {
+		return nil, false, err
+	}
+	return verifier, true, nil
+}
+
+// verifyChannelBindingFlag verifies the GS2 channel binding flag of the client-first-message.
+func (server *SCRAMServer) verifyChannelBindingFlag(cbFlag string) error {
+	// RFC 5802: 6. Channel Binding
+	switch {
+	case cbFlag == string(gss.ClientDoesNotSupportCBSFlag):
+		if server.mechanism == SCRAMSHA256Plus {
+			return scram.ErrChannelBindingsDontMatch
+		}
+	case cbFlag == string(gss.ClientSupportsCBSFlag):
+		// The client supports channel binding but thinks the server does not.
+		// This is a downgrade attack if the server has advertised SCRAM-SHA-256-PLUS.
+		if server.mechanism == SCRAMSHA256Plus || server.cbData != nil {
+			return scram.ErrServerDoesSupportChannelBinding
+		}
+	case strings.HasPrefix(cbFlag, string(gss.ClientSupportsUsedCBSFlag)+"="):
+		if server.mechanism != SCRAMSHA256Plus || server.cbData == nil {
+			return scram.ErrChannelBindingNotSupported
+		}
+		if cbFlag[2:] != TLSServerEndPoint {
+			return scram.ErrUnsupportedChannelBindingType
+		}
+	default:
+		return newErrInvalidSCRAMMessage(cbFlag)
+	}
+	return nil
+}
+
+// FirstMessageFrom handles the client-first-message and returns the server-first-message.
+func (server *SCRAMServer) FirstMessageFrom(data []byte) ([]byte, error) {
+	// RFC 5802: 7. Formal Syntax
+	// client-first-message = gs2-header client-first-message-bare
+	// gs2-header           = gs2-cbind-flag "," [ authzid ] ","
+	clientFirstMsg := string(data)
+	props := strings.SplitN(clientFirstMsg, ",", 3)
+	if len(props) != 3 {
+		return nil, newErrInvalidSCRAMMessage(clientFirstMsg)
+	}
+	cbFlag, authzID, clientFirstMsgBare := props[0], props[1], props[2]
+	err := server.verifyChannelBindingFlag(cbFlag)
+	if err != nil {
+		return nil, err
+	}
+	if 0 < len(authzID) {
+		return nil, scram.ErrExtensionsNotSupported
+	}
+
+	msg, err := scram.NewMessageFromString(clientFirstMsgBare)
+	if err != nil {
+		return nil, newErrInvalidSCRAMMessage(clientFirstMsg)
+	}
+	if _, ok := msg.FutureFutureExtensibility(); ok {
+		return nil, scram.ErrExtensionsNotSupported
+	}
+	clientNonce, ok := msg.RandomSequence()
+	if !ok || len(clientNonce) == 0 {
+		return nil, newErrInvalidSCRAMMessage(clientFirstMsg)
+	}
+
+	verifier, ok, err := server.lookupVerifier()
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		// Continue the exchange with a mock verifier not to reveal whether the user exists.
+		verifier, err = NewSCRAMMockVerifier(server.username, server.mockNonce)
+		if err != nil {
+			return nil, err
+		}
+		server.isMock = true
+	}
+
+	serverNonce, err := rand.NewRandomSequence(scramNonceLength)
+	if err != nil {
+		return nil, err
+	}
+
+	server.verifier = verifier
+	server.gs2Header = cbFlag + "," + authzID + ","
+	server.clientFirstMsgBare = clientFirstMsgBare
+	server.nonce = clientNonce + serverNonce.String()
+
+	serverFirstMsg := scram.NewMessage()
+	serverFirstMsg.SetRandomSequence(server.nonce)
+	serverFirstMsg.SetSaltBytes(verifier.Salt)
+	serverFirstMsg.SetIterationCount(verifier.IterationCount)
+	server.serverFirstMsg = serverFirstMsg.String()
+
+	return []byte(server.serverFirstMsg), nil
+}
+
+// FinalMessageFrom handles the client-final-message and returns the server-final-message.
+func (server *SCRAMServer) FinalMessageFrom(data []byte) ([]byte, error) {
+	if server.verifier == nil {
+		return nil, scram.ErrOtherError
+	}
+
+	// RFC 5802: 7. Formal Syntax
+	// client-final-message-without-proof = channel-binding "," nonce ["," extensions]
+	// client-final-message               = client-final-message-without-proof "," proof
+	clientFinalMsg := string(data)
+	proofIdx := strings.LastIndex(clientFinalMsg, ","+scram.ClientProofAttr+"=")
+	if proofIdx < 0 {
+		return nil, newErrInvalidSCRAMMessage(clientFinalMsg)
+	}
+	clientFinalMsgWithoutProof := clientFinalMsg[:proofIdx]
+
+	msg, err := scram.NewMessageFromString(clientFinalMsg)
+	if err != nil {
+		return nil, newErrInvalidSCRAMMessage(clientFinalMsg)
+	}
+
+	// The channel binding attribute is the GS2 header followed by the channel binding data
+	// when the client uses SCRAM-SHA-256-PLUS.
+	cbData, ok := msg.ChannelBindingData()
+	if !ok {
+		return nil, newErrInvalidSCRAMMessage(clientFinalMsg)
+	}
+	cbInput := []byte(server.gs2Header)
+	if server.mechanism == SCRAMSHA256Plus {
+		cbInput = append(cbInput, server.cbData...)
+	}
+	if cbData != base64.StdEncoding.EncodeToString(cbInput) {
+		return nil, scram.ErrChannelBindingsDontMatch
+	}
+
+	nonce, ok := msg.RandomSequence()
+	if !ok || nonce != server.nonce {
+		return nil, newErrInvalidSCRAMMessage(clientFinalMsg)
+	}
+
+	hashFunc := scram.HashSHA256()
+	clientProof, ok := msg.ClientProof()
+	if !ok || len(clientProof) != hashFunc().Size() {
+		return nil, scram.ErrInvalidProof
+	}
+
+	// ClientSignature := HMAC(StoredKey, AuthMessage)
+	// ClientKey       := ClientProof XOR ClientSignature
+	// StoredKey       := H(ClientKey)
+	authMsg := scram.AuthMessage(server.clientFirstMsgBare, server.serverFirstMsg, clientFinalMsgWithoutProof)
+	clientSignature := scram.HMAC(hashFunc, server.verifier.StoredKey, []byte(authMsg))
+	clientKey := scram.XOR(clientProof, clientSignature)
+	if server.isMock || !hmac.Equal(scram.H(hashFunc, clientKey), server.verifier.StoredKey) {
+		return nil, NewErrAuthrizationFailed(server.username)
+	}
+
+	// ServerSignature := HMAC(ServerKey, AuthMessage)
+	serverFinalMsg := scram.NewMessage()
+	serverFinalMsg.SetServerSignature(scram.HMAC(hashFunc, server.verifier.ServerKey, []byte(authMsg)))
+
+	return []byte(serverFinalMsg.String()), nil
+}
@@ -0,0 +1,273 @@
+// Copyright (C) 2019 The go-postgresql Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/cybergarage/go-sasl/sasl/scram"
+)
+
+// testCredentialStore represents a credential store of the plaintext passwords or the SCRAM verifiers.
+type testCredentialStore map[string]string
+
+func (store testCredentialStore) LookupCredential(q Query) (Credential, bool, error) {
+	password, ok := store[q.Username()]
+	if !ok {
+		return nil, false, nil
+	}
+	cred := NewCredential(
+		WithCredentialUsername(q.Username()),
+		WithCredentialPassword(password),
+	)
+	return cred, true, nil
+}
+
+// testSCRAMClient represents a client side SCRAM-SHA-256 exchange.
+type testSCRAMClient struct {
+	gs2Header          string
+	cbData             []byte
+	password           string
+	clientFirstMsgBare string
+	serverKey          []byte
+	authMsg            string
+}
+
+func newTestSCRAMClient(gs2Header string, cbData []byte, password string) *testSCRAMClient {
+	return &testSCRAMClient{
+		gs2Header:          gs2Header,
+		cbData:             cbData,
+		password:           password,
+		clientFirstMsgBare: "n=,r=rOprNGfwEbeRWgbNEkqO",
+		serverKey:          nil,
+		authMsg:            "",
+	}
+}
+
+func (client *testSCRAMClient) FirstMessage() []byte {
+	return []byte(client.gs2Header + client.clientFirstMsgBare)
+}
+
+func (client *testSCRAMClient) FinalMessageFrom(t *testing.T, serverFirstMsg []byte) []byte {
+	t.Helper()
+
+	msg, err := scram.NewMessageFromString(string(serverFirstMsg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	nonce, _ := msg.RandomSequence()
+	salt, _ := msg.Salt()
+	iterationCount, _ := msg.IterationCount()
+
+	hashFunc := scram.HashSHA256()
+	saltedPassword := scram.Hi(hashFunc, client.password, salt, iterationCount)
+	clientKey := scram.ClientKey(hashFunc, saltedPassword)
+	storedKey := scram.StoredKey(hashFunc, clientKey)
+
+	cbInput := append([]byte(client.gs2Header), client.cbData...)
+	clientFinalMsgWithoutProof := "c=" + base64.StdEncoding.EncodeToString(cbInput) + ",r=" + nonce
+	client.authMsg = scram.AuthMessage(client.clientFirstMsgBare, string(serverFirstMsg), clientFinalMsgWithoutProof)
+	client.serverKey = scram.ServerKey(hashFunc, saltedPassword)
+	clientProof := scram.XOR(clientKey, scram.HMAC(hashFunc, storedKey, []byte(client.authMsg)))
+
+	return []byte(clientFinalMsgWithoutProof + ",p=" + base64.StdEncoding.EncodeToString(clientProof))
+}
+
+func (client *testSCRAMClient) VerifyServerFinalMessage(serverFinalMsg []byte) bool {
+	msg, err := scram.NewMessageFromString(string(serverFinalMsg))
+	if err != nil {
+		return false
+	}
+	serverSignature, ok := msg.ServerSignature()
+	if !ok {
+		return false
+	}
+	return bytes.Equal(serverSignature, scram.HMAC(scram.HashSHA256(), client.serverKey, []byte(client.authMsg)))
+}
+
+func TestSCRAMServer(t *testing.T) {
+	const (
+		username = "scramuser"
+		password = "scrampassword"
+	)
+
+	verifier, err := NewSCRAMVerifierFromPassword(password)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		storedPassword string
+		clientPassword string
+		expected       bool
+	}{
+		{password, password, true},
+		{verifier.String(), password, true},
+		{password, "invalid" + password, false},
+		{verifier.String(), "invalid" + password, false},
+		{NewMD5Password(username, password), password, false},
+	}
+
+	for _, test := range tests {
+		server := NewSCRAMServer(
+			WithSCRAMServerCredentialStore(testCredentialStore{username: test.storedPassword}),
+			WithSCRAMServerUsername(username),
+		)
+		client := newTestSCRAMClient("n,,", nil, test.clientPassword)
+
+		serverFirstMsg, err := server.FirstMessageFrom(client.FirstMessage())
+		if err != nil {
+			t.Errorf("%s: %s", test.storedPassword, err)
+			continue
+		}
+		serverFinalMsg, err := server.FinalMessageFrom(client.FinalMessageFrom(t, serverFirstMsg))
+		if !test.expected {
+			if !errors.Is(err, ErrAuthrizationFailed) {
+				t.Errorf("%s: %v (expected %s)", test.storedPassword, err, ErrAuthrizationFailed)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %s", test.storedPassword, err)
+			continue
+		}
+		if !client.VerifyServerFinalMessage(serverFinalMsg) {
+			t.Errorf("%s: server signature (%s) is invalid", test.storedPassword, serverFinalMsg)
+		}
+	}
+}
+
+func TestSCRAMServerChannelBinding(t *testing.T) {
+	const (
+		username = "scramplususer"
+		password = "scrampluspassword"
+	)
+
+	cbData := []byte("tls-server-end-point data")
+
+	tests := []struct {
+		mechanism    string
+		gs2Header    string
+		clientCBData []byte
+		expected     error
+	}{
+		{SCRAMSHA256Plus, "p=tls-server-end-point,,", cbData, nil},
+		{SCRAMSHA256, "y,,", nil, scram.ErrServerDoesSupportChannelBinding},
+		{SCRAMSHA256Plus, "n,,", nil, scram.ErrChannelBindingsDontMatch},
+		{SCRAMSHA256Plus, "p=tls-unique,,", cbData, scram.ErrUnsupportedChannelBindingType},
+		{SCRAMSHA256Plus, "p=tls-server-end-point,,", []byte("other data"), scram.ErrChannelBindingsDontMatch},
+		{SCRAMSHA256, "p=tls-server-end-point,,", cbData, scram.ErrChannelBindingNotSupported},
+		{SCRAMSHA256, "n,,", nil, nil},
+	}
+
+	for _, test := range tests {
+		server := NewSCRAMServer(
+			WithSCRAMServerCredentialStore(testCredentialStore{username: password}),
+			WithSCRAMServerUsername(username),
+			WithSCRAMServerMechanism(test.mechanism),
+			WithSCRAMServerChannelBinding(cbData),
+		)
+		client := newTestSCRAMClient(test.gs2Header, test.clientCBData, password)
+
+		serverFirstMsg, err := server.FirstMessageFrom(client.FirstMessage())
+		if err == nil {
+			_, err = server.FinalMessageFrom(client.FinalMessageFrom(t, serverFirstMsg))
+		}
+		if test.expected == nil {
+			if err != nil {
+				t.Errorf("%s %s: %s", test.mechanism, test.gs2Header, err)
+			}
+			continue
+		}
+		if !errors.Is(err, test.expected) {
+			t.Errorf("%s %s: %v (expected %s)", test.mechanism, test.gs2Header, err, test.expected)
+		}
+	}
+}
+
+func TestSCRAMServerMockVerifier(t *testing.T) {
+	nonce, err := NewSCRAMMockNonce()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	firstMessageFrom := func(username string, nonce []byte) *scram.Message {
+		server := NewSCRAMServer(
+			WithSCRAMServerCredentialStore(testCredentialStore{}),
+			WithSCRAMServerUsername(username),
+			WithSCRAMServerMockNonce(nonce),
+		)
+		serverFirstMsg, err := server.FirstMessageFrom(newTestSCRAMClient("n,,", nil, "").FirstMessage())
+		if err != nil {
+			t.Fatal(err)
+		}
+		msg, err := scram.NewMessageFromString(string(serverFirstMsg))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return msg
+	}
+
+	saltOf := func(msg *scram.Message) []byte {
+		salt, _ := msg.Salt()
+		return salt
+	}
+
+	// The salt of the unknown user is the same between the attempts as the salt of existing users.
+
+	msg := firstMessageFrom("nosuchuser", nonce)
+	otherMsg := firstMessageFrom("nosuchuser", nonce)
+	if len(saltOf(msg)) != SCRAMDefaultSaltLength || !bytes.Equal(saltOf(msg), saltOf(otherMsg)) {
+		t.Errorf("salts (%v, %v) are not the same", saltOf(msg), saltOf(otherMsg))
+	}
+	if iterationCount, _ := msg.IterationCount(); iterationCount != SCRAMDefaultIterationCount {
+		t.Errorf("iteration count (%d) != %d", iterationCount, SCRAMDefaultIterationCount)
+	}
+
+	// The salt depends on the user and the server-wide nonce.
+
+	otherMsg = firstMessageFrom("otheruser", nonce)
+	if bytes.Equal(saltOf(msg), saltOf(otherMsg)) {
+		t.Errorf("salts of different users are the same")
+	}
+	otherNonce, err := NewSCRAMMockNonce()
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherMsg = firstMessageFrom("nosuchuser", otherNonce)
+	if bytes.Equal(saltOf(msg), saltOf(otherMsg)) {
+		t.Errorf("salts of different nonces are the same")
+	}
+
+	// The exchange of the unknown user always fails even with the empty password of the mock verifier.
+
+	server := NewSCRAMServer(
+		WithSCRAMServerCredentialStore(testCredentialStore{}),
+		WithSCRAMServerUsername("nosuchuser"),
+		WithSCRAMServerMockNonce(nonce),
+	)
+	client := newTestSCRAMClient("n,,", nil, "")
+	serverFirstMsg, err := server.FirstMessageFrom(client.FirstMessage())
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = server.FinalMessageFrom(client.FinalMessageFrom(t, serverFirstMsg))
+	if !errors.Is(err, ErrAuthrizationFailed) {
+		t.Errorf("%v (expected %s)", err, ErrAuthrizationFailed)
+	}
+}
@@ -0,0 +1,165 @@
+// Copyright (C) 2019 The go-postgresql Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/cybergarage/go-sasl/sasl/prep"
+	"github.com/cybergarage/go-sasl/sasl/scram"
+)
+
+// PostgreSQL: Documentation: 17: 53.3. SASL Authentication
+// https://www.postgresql.org/docs/current/sasl-authentication.html
+// RFC 7677 - SCRAM-SHA-256 and SCRAM-SHA-256-PLUS Simple Authentication and Security Layer (SASL) Mechanisms
+// https://datatracker.ietf.org/doc/html/rfc7677
+
+const (
+	// SCRAMSHA256 is the SCRAM-SHA-256 mechanism name.
+	SCRAMSHA256 = "SCRAM-SHA-256"
+	// SCRAMDefaultIterationCount is the default iteration count for SCRAM verifiers.
+	SCRAMDefaultIterationCount = 4096
+	// SCRAMDefaultSaltLength is the default salt length for SCRAM verifiers.
+	SCRAMDefaultSaltLength = 16
+	// SCRAMMockNonceLength is the length of the nonce to derive the salts of mock SCRAM verifiers.
+	SCRAMMockNonceLength = 32
+)
+
+// SCRAMVerifier represents a SCRAM-SHA-256 verifier which is stored instead of a plaintext password.
+// The string format is compatible with the rolpassword column of PostgreSQL:
+// SCRAM-SHA-256$<iteration count>:<salt>$<StoredKey>:<ServerKey>.
+type SCRAMVerifier struct {
+	IterationCount int
+	Salt           []byte
+	StoredKey      []byte
+	ServerKey      []byte
+}
+
+// NewSCRAMVerifierFromPassword returns a new SCRAM verifier for the specified password with a random salt.
+func NewSCRAMVerifierFromPassword(password string) (*SCRAMVerifier, error) {
+	salt := make([]byte, SCRAMDefaultSaltLength)
+	_, err := rand.Read(salt)
+	if err != nil {
+		return nil, err
+	}
+	return NewSCRAMVerifierWith(password, salt, SCRAMDefaultIterationCount)
+}
+
+// NewSCRAMMockNonce returns a new random nonce to derive the salts of mock SCRAM verifiers.
+// A server should generate the nonce once and use it for all connections.
+func NewSCRAMMockNonce() ([]byte, error) {
+	nonce := make([]byte, SCRAMMockNonceLength)
+	_, err := rand.Read(nonce)
+	if err != nil {
+		return nil, err
+	}
+	return nonce, nil
+}
+
+// NewSCRAMMockVerifier returns a mock SCRAM verifier for the specified user who has no password.
+// As scram_mock_salt() of PostgreSQL, the salt is derived from the username and the specified mock nonce,
+// so the salt is the same for every authentication attempt of the user, as it is for existing users.
+func NewSCRAMMockVerifier(username string, nonce []byte) (*SCRAMVerifier, error) {
+	hash := sha256.New()
+	hash.Write([]byte(username))
+	hash.Write(nonce)
+	salt := hash.Sum(nil)[:SCRAMDefaultSaltLength]
+	return NewSCRAMVerifierWith("", salt, SCRAMDefaultIterationCount)
+}
+
+// NewSCRAMVerifierWith returns a new SCRAM verifier for the specified password, salt and iteration count.
+func NewSCRAMVerifierWith(password string, salt []byte, iterationCount int) (*SCRAMVerifier, error) {
+	if iterationCount <= 1 {
+		return nil, newErrInvalidSCRAMVerifier()
+	}
+	hashFunc := scram.HashSHA256()
+	// PostgreSQL uses the raw password when SASLprep fails.
+	normPassword, err := prep.Normalize(password)
+	if err != nil {
+		normPassword = password
+	}
+	saltedPassword := scram.Hi(hashFunc, normPassword, salt, iterationCount)
+	clientKey := scram.ClientKey(hashFunc, saltedPassword)
+	return &SCRAMVerifier{
+		IterationCount: iterationCount,
+		Salt:           salt,
+		StoredKey:      scram.StoredKey(hashFunc, clientKey),
+		ServerKey:      scram.ServerKey(hashFunc, saltedPassword),
+	}, nil
+}
+
+// IsSCRAMVerifier returns true if the specified string is a SCRAM-SHA-256 verifier.
+func IsSCRAMVerifier(s string) bool {
+	_, err := NewSCRAMVerifierFromString(s)
+	return err == nil
+}
+
+// NewSCRAMVerifierFromString returns a new SCRAM verifier from the specified verifier string.
+func NewSCRAMVerifierFromString(s string) (*SCRAMVerifier, error) {
+	parts := strings.Split(s, "$")
+	if len(parts) != 3 || parts[0] != SCRAMSHA256 {
+		return nil, newErrInvalidSCRAMVerifier()
+	}
+	iterSalt := strings.Split(parts[1], ":")
+	keys := strings.Split(parts[2], ":")
+	if len(iterSalt) != 2 || len(keys) != 2 {
+		return nil, newErrInvalidSCRAMVerifier()
+	}
+	iterationCount, err := strconv.Atoi(iterSalt[0])
+	if err != nil || iterationCount <= 1 {
+		return nil, newErrInvalidSCRAMVerifier()
+	}
+	decode := func(v string) ([]byte, error) {
+		b, err := base64.StdEncoding.DecodeString(v)
+		if err != nil {
+			return nil, newErrInvalidSCRAMVerifier()
+		}
+		return b, nil
+	}
+	salt, err := decode(iterSalt[1])
+	if err != nil {
+		return nil, err
+	}
+	storedKey, err := decode(keys[0])
+	if err != nil {
+		return nil, err
+	}
+	serverKey, err := decode(keys[1])
+	if err != nil {
+		return nil, err
+	}
+	return &SCRAMVerifier{
+		IterationCount: iterationCount,
+		Salt:           salt,
+		StoredKey:      storedKey,
+		ServerKey:      serverKey,
+	}, nil
+}
+
+// String returns the verifier string.
+func (v *SCRAMVerifier) String() string {
+	enc := base64.StdEncoding.EncodeToString
+	return fmt.Sprintf("%s$%d:%s$%s:%s",
+		SCRAMSHA256,
+		v.IterationCount,
+		enc(v.Salt),
+		enc(v.StoredKey),
+		enc(v.ServerKey))
+}
@@ -0,0 +1,28 @@
+// Copyright (C) 2019 The go-postgresql Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgresql
+
+import (
+	"github.com/cybergarage/go-postgresql/postgresql/auth"
+	"github.com/cybergarage/go-postgresql/postgresql/protocol"
+)
+
+// AuthMethodPolicyFunc is an adapter to allow the use of ordinary functions as authentication method policies.
+type AuthMethodPolicyFunc func(Conn, *protocol.Startup) (auth.Method, error)
+
+// AuthenticationMethod calls the function.
+func (fn AuthMethodPolicyFunc) AuthenticationMethod(conn Conn, msg *protocol.Startup) (auth.Method, error) {
+	return fn(conn, msg)
+}
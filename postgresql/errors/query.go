@@ -15,32 +15,74 @@
 package errors
 
 import (
+	"errors"
 	"fmt"
 )
 
+// ErrSyntax is returned when the query has a syntax error.
+var ErrSyntax = errors.New("syntax error")
+
+// ErrDatabaseNotExist is returned when the specified database does not exist.
+var ErrDatabaseNotExist = fmt.Errorf("%w", ErrNotExist)
+
+// ErrTableNotExist is returned when the specified table does not exist.
+var ErrTableNotExist = fmt.Errorf("%w", ErrNotExist)
+
+// ErrColumnNotExist is returned when the specified column does not exist.
+var ErrColumnNotExist = fmt.Errorf("%w", ErrNotExist)
+
+// ErrDatabaseExist is returned when the specified database already exists.
+var ErrDatabaseExist = fmt.Errorf("%w", ErrExist)
+
+// ErrTableExist is returned when the specified table already exists.
+var ErrTableExist = fmt.Errorf("%w", ErrExist)
+
+// ErrColumnsNotEqual is returned when the number of columns is not equal to the number of schema columns.
+var ErrColumnsNotEqual = fmt.Errorf("%w", ErrNotEqual)
+
+// ErrPreparedStatementNotExist is returned when the specified prepared statement does not exist.
+var ErrPreparedStatementNotExist = fmt.Errorf("%w", ErrNotExist)
+
+// ErrPreparedStatementExist is returned when the specified prepared statement already exists.
+var ErrPreparedStatementExist = fmt.Errorf("%w", ErrExist)
+
+// ErrPreparedPortalNotExist is returned when the specified portal does not exist.
+var ErrPreparedPortalNotExist = fmt.Errorf("%w", ErrNotExist)
+
+// ErrQueryCanceled is returned when the running query is canceled by a CancelRequest.
+var ErrQueryCanceled = errors.New("canceling statement due to user request")
+
+// ErrNoTable is returned when the query has no table.
+var ErrNoTable = errors.New("no table specified")
+
+// NewErrSyntax returns a new syntax error which wraps the specified parser error.
+func NewErrSyntax(err error) error {
+	return fmt.Errorf("%w : %w", ErrSyntax, err)
+}
+
 // NewErrDatabaseNotExist returns a new database not exist error.
 func NewErrDatabaseNotExist(v string) error {
-	return fmt.Errorf("database (%v) is %w", v, ErrNotExist)
+	return fmt.Errorf("database (%v) is %w", v, ErrDatabaseNotExist)
 }
 
 // NewErrTableNotExist returns a new table not exist error.
 func NewErrTableNotExist(v string) error {
-	return fmt.Errorf("table (%v) is %w", v, ErrNotExist)
+	return fmt.Errorf("table (%v) is %w", v, ErrTableNotExist)
 }
 
 // NewErrDatabaseExist returns a new database exist error.
 func NewErrDatabaseExist(v string) error {
-	return fmt.Errorf("database (%v) is %w", v, ErrExist)
+	return fmt.Errorf("database (%v) is %w", v, ErrDatabaseExist)
 }
 
 // NewErrTableExist returns a new table exist error.
 func NewErrTableExist(v string) error {
-	return fmt.Errorf("table (%v) is %w", v, ErrExist)
+	return fmt.Errorf("table (%v) is %w", v, ErrTableExist)
 }
 
 // NewErrColumnNotExist returns a new column not exist error.
 func NewErrColumnNotExist(v any) error {
-	return fmt.Errorf("column (%v) is %w", v, ErrNotExist)
+	return fmt.Errorf("column (%v) is %w", v, ErrColumnNotExist)
 }
 
 // NewErrColumnValueNotExist returns a new column value not exist error.
@@ -55,17 +97,27 @@ func NewErrGroupByColumnValueNotFound(v any) error {
 
 // NewErrColumnsNotEqual returns a new columns not equal error.
 func NewErrColumnsNotEqual(v1, v2 int) error {
-	return fmt.Errorf("the number of columns (%d) is %w to the number of schema columns (%d)", v1, ErrNotEqual, v2)
+	return fmt.Errorf("the number of columns (%d) is %w to the number of schema columns (%d)", v1, ErrColumnsNotEqual, v2)
 }
 
 // NewErrPreparedStatementNotExist returns a new prepared statement not exist error.
 func NewErrPreparedStatementNotExist(name string) error {
-	return fmt.Errorf("prepared statement (%v) is %w", name, ErrNotExist)
+	return fmt.Errorf("prepared statement (%v) is %w", name, ErrPreparedStatementNotExist)
+}
+
+// NewErrPreparedStatementExist returns a new prepared statement exist error.
+func NewErrPreparedStatementExist(name string) error {
+	return fmt.Errorf("prepared statement (%v) is %w", name, ErrPreparedStatementExist)
+}
+
+// NewErrPreparedStatementParamsNotEqual returns a new error for the wrong number of the prepared statement parameters.
+func NewErrPreparedStatementParamsNotEqual(name string, expected int, actual int) error {
+	return fmt.Errorf("%w: prepared statement (%v) requires %d parameters, but got %d", ErrSyntax, name, expected, actual)
 }
 
 // NewErrPreparedPortalNotExist returns a new prepared portal not exist error.
 func NewErrPreparedPortalNotExist(name string) error {
-	return fmt.Errorf("prepared portal (%v) is %w", name, ErrNotExist)
+	return fmt.Errorf("prepared portal (%v) is %w", name, ErrPreparedPortalNotExist)
 }
 
 // NewErrMultiplePreparedStatementNotSupported returns a new prepared statement multi statement error.
@@ -77,3 +129,13 @@ func NewErrMultiplePreparedStatementNotSupported(query string) error {
 func NewErrMultipleTableNotSupported(query string) error {
 	return fmt.Errorf("multiple table (%v) is %w", query, ErrNotSupported)
 }
+
+// NewErrNoTable returns a new no table specified error.
+func NewErrNoTable(query string) error {
+	return fmt.Errorf("%w in query (%v)", ErrNoTable, query)
+}
+
+// NewErrQueryCanceled returns a new query canceled error which wraps the specified context error.
+func NewErrQueryCanceled(err error) error {
+	return fmt.Errorf("%w (%w)", ErrQueryCanceled, err)
+}
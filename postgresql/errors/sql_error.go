@@ -0,0 +1,223 @@
+// Copyright (C) 2019 The go-postgresql Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"errors"
+
+	sqlerrors "github.com/cybergarage/go-sqlparser/sql/errors"
+)
+
+// PostgreSQL: Documentation: 16: 55.8. Error and Notice Message Fields
+// https://www.postgresql.org/docs/16/protocol-error-fields.html
+// PostgreSQL: Appendix A. PostgreSQL Error Codes
+// https://www.postgresql.org/docs/current/errcodes-appendix.html
+
+// Code represents a SQLSTATE error code.
+type Code = sqlerrors.Code
+
+// Severity represents a severity of an error.
+type Severity string
+
+const (
+	ErrorSeverity   Severity = "ERROR"
+	FatalSeverity   Severity = "FATAL"
+	PanicSeverity   Severity = "PANIC"
+	WarningSeverity Severity = "WARNING"
+	NoticeSeverity  Severity = "NOTICE"
+	DebugSeverity   Severity = "DEBUG"
+	InfoSeverity    Severity = "INFO"
+	LogSeverity     Severity = "LOG"
+)
+
+// SQLError represents an error with a SQLSTATE code and the optional fields of the PostgreSQL error response.
+// The zero values of the optional fields are not sent to the client.
+type SQLError struct {
+	Severity         Severity
+	Code             Code
+	Message          string
+	Detail           string
+	Hint             string
+	Position         int
+	InternalPosition int
+	InternalQuery    string
+	Where            string
+	Schema           string
+	Table            string
+	Column           string
+	DataTypeName     string
+	Constraint       string
+	File             string
+	Line             int
+	Routine          string
+	err              error
+}
+
+// SQLErrorOption represents an option function for a SQL error.
+type SQLErrorOption func(*SQLError)
+
+// WithSQLErrorSeverity returns an option to set the severity.
+func WithSQLErrorSeverity(severity Severity) SQLErrorOption {
+	return func(e *SQLError) {
+		e.Severity = severity
+	}
+}
+
+// WithSQLErrorDetail returns an option to set the detail message.
+func WithSQLErrorDetail(detail string) SQLErrorOption {
+	return func(e *SQLError) {
+		e.Detail = detail
+	}
+}
+
+// WithSQLErrorHint returns an option to set the hint message.
+func WithSQLErrorHint(hint string) SQLErrorOption {
+	return func(e *SQLError) {
+		e.Hint = hint
+	}
+}
+
+// WithSQLErrorPosition returns an option to set the one-based cursor position in the original query string.
+func WithSQLErrorPosition(pos int) SQLErrorOption {
+	return func(e *SQLError) {
+		e.Position = pos
+	}
+}
+
+// WithSQLErrorInternalQuery returns an option to set the internally generated query and the one-based cursor position in it.
+func WithSQLErrorInternalQuery(query string, pos int) SQLErrorOption {
+	return func(e *SQLError) {
+		e.InternalQuery = query
+		e.InternalPosition = pos
+	}
+}
+
+// WithSQLErrorWhere returns an option to set the context in which the error occurred.
+func WithSQLErrorWhere(where string) SQLErrorOption {
+	return func(e *SQLError) {
+		e.Where = where
+	}
+}
+
+// WithSQLErrorSchema returns an option to set the schema name.
+func WithSQLErrorSchema(schema string) SQLErrorOption {
+	return func(e *SQLError) {
+		e.Schema = schema
+	}
+}
+
+// WithSQLErrorTable returns an option to set the table name.
+func WithSQLErrorTable(table string) SQLErrorOption {
+	return func(e *SQLError) {
+		e.Table = table
+	}
+}
+
+// WithSQLErrorColumn returns an option to set the column name.
+func WithSQLErrorColumn(column string) SQLErrorOption {
+	return func(e *SQLError) {
+		e.Column = column
+	}
+}
+
+// WithSQLErrorDataTypeName returns an option to set the data type name.
+func WithSQLErrorDataTypeName(name string) SQLErrorOption {
+	return func(e *SQLError) {
+		e.DataTypeName = name
+	}
+}
+
+// WithSQLErrorConstraint returns an option to set the constraint name.
+func WithSQLErrorConstraint(constraint string) SQLErrorOption {
+	return func(e *SQLError) {
+		e.Constraint = constraint
+	}
+}
+
+// WithSQLErrorSource returns an option to set the source code location where the error was reported.
+func WithSQLErrorSource(file string, line int, routine string) SQLErrorOption {
+	return func(e *SQLError) {
+		e.File = file
+		e.Line = line
+		e.Routine = routine
+	}
+}
+
+// NewSQLError returns a new SQL error with the specified SQLSTATE code and message.
+func NewSQLError(code Code, msg string, opts ...SQLErrorOption) *SQLError {
+	e := &SQLError{
+		Severity:         ErrorSeverity,
+		Code:             code,
+		Message:          msg,
+		Detail:           "",
+		Hint:             "",
+		Position:         0,
+		InternalPosition: 0,
+		InternalQuery:    "",
+		Where:            "",
+		Schema:           "",
+		Table:            "",
+		Column:           "",
+		DataTypeName:     "",
+		Constraint:       "",
+		File:             "",
+		Line:             0,
+		Routine:          "",
+		err:              nil,
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// NewSQLErrorWith returns a new SQL error with the specified SQLSTATE code which wraps the specified error.
+func NewSQLErrorWith(code Code, err error, opts ...SQLErrorOption) *SQLError {
+	e := NewSQLError(code, err.Error(), opts...)
+	e.err = err
+	return e
+}
+
+// NewNotice returns a new notice with the specified SQLSTATE code and message.
+// Notices have the same fields as errors, and the severity is NOTICE unless the option sets.
+func NewNotice(code Code, msg string, opts ...SQLErrorOption) *SQLError {
+	opts = append([]SQLErrorOption{WithSQLErrorSeverity(NoticeSeverity)}, opts...)
+	return NewSQLError(code, msg, opts...)
+}
+
+// NewWarning returns a new warning notice with the specified SQLSTATE code and message.
+func NewWarning(code Code, msg string, opts ...SQLErrorOption) *SQLError {
+	opts = append([]SQLErrorOption{WithSQLErrorSeverity(WarningSeverity)}, opts...)
+	return NewSQLError(code, msg, opts...)
+}
+
+// AsSQLError returns the first SQL error in the specified error tree.
+func AsSQLError(err error) (*SQLError, bool) {
+	var e *SQLError
+	if !errors.As(err, &e) {
+		return nil, false
+	}
+	return e, true
+}
+
+// Error returns the error message.
+func (e *SQLError) Error() string {
+	return e.Message
+}
+
+// Unwrap returns the wrapped error.
+func (e *SQLError) Unwrap() error {
+	return e.err
+}
@@ -0,0 +1,90 @@
+// Copyright (C) 2019 The go-postgresql Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"errors"
+	"strings"
+
+	sqlerrors "github.com/cybergarage/go-sqlparser/sql/errors"
+)
+
+// go-sqlparser returns the specific errors such as the table not exist error
+// which wrap only the generic sentinel errors, so they are identified by the messages
+// of the constructors to be mapped to the specific sentinel errors of this package.
+
+const sqlParserErrorPlaceholder = "\x00"
+
+type sqlParserErrorFormat struct {
+	prefix string
+	suffix string
+	target error
+}
+
+var sqlParserErrorFormats = []sqlParserErrorFormat{
+	newSQLParserErrorFormat(sqlerrors.NewErrDatabaseNotExist, ErrDatabaseNotExist),
+	newSQLParserErrorFormat(sqlerrors.NewErrTableNotExist, ErrTableNotExist),
+	newSQLParserErrorFormat(sqlerrors.NewErrDatabaseExist, ErrDatabaseExist),
+	newSQLParserErrorFormat(sqlerrors.NewErrTableExist, ErrTableExist),
+	newSQLParserErrorFormat(func(v string) error { return sqlerrors.NewErrColumnNotExist(v) }, ErrColumnNotExist),
+	newSQLParserErrorFormat(sqlerrors.NewErrPreparedStatementNotExist, ErrPreparedStatementNotExist),
+	newSQLParserErrorFormat(sqlerrors.NewErrPreparedPortalNotExist, ErrPreparedPortalNotExist),
+	newSQLParserErrorFormat(sqlerrors.NewErrNoTable, ErrNoTable),
+}
+
+func newSQLParserErrorFormat(newErr func(string) error, target error) sqlParserErrorFormat {
+	prefix, suffix, _ := strings.Cut(newErr(sqlParserErrorPlaceholder).Error(), sqlParserErrorPlaceholder)
+	return sqlParserErrorFormat{
+		prefix: prefix,
+		suffix: suffix,
+		target: target,
+	}
+}
+
+func (format sqlParserErrorFormat) matches(err error) bool {
+	msg := err.Error()
+	return len(format.prefix)+len(format.suffix) < len(msg) &&
+		strings.HasPrefix(msg, format.prefix) &&
+		strings.HasSuffix(msg, format.suffix)
+}
+
+// sqlParserError represents a specific error of go-sqlparser which also wraps the specific sentinel error of this package.
+type sqlParserError struct {
+	err    error
+	target error
+}
+
+// Error returns the message of the original error.
+func (e *sqlParserError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the original error and the specific sentinel error.
+func (e *sqlParserError) Unwrap() []error {
+	return []error{e.err, e.target}
+}
+
+// wrapSQLParserError returns the specified error wrapping the specific sentinel error of this package
+// if the error tree has a specific error of go-sqlparser, otherwise returns the specified error.
+func wrapSQLParserError(err error) error {
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		for _, format := range sqlParserErrorFormats {
+			if format.matches(e) {
+				return &sqlParserError{err: err, target: format.target}
+			}
+		}
+	}
+	return err
+}
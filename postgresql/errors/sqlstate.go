@@ -0,0 +1,134 @@
+// Copyright (C) 2019 The go-postgresql Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	sqlerrors "github.com/cybergarage/go-sqlparser/sql/errors"
+	"github.com/cybergarage/go-sqlparser/sql/fn"
+	"github.com/cybergarage/go-sqlparser/sql/query"
+)
+
+// PostgreSQL: Appendix A. PostgreSQL Error Codes
+// https://www.postgresql.org/docs/current/errcodes-appendix.html
+
+type sqlStateEntry struct {
+	target error
+	code   Code
+}
+
+// sqlStateRegistry represents a registry of the SQLSTATE codes for the sentinel errors.
+type sqlStateRegistry struct {
+	sync.RWMutex
+	entries []sqlStateEntry
+}
+
+var sqlStates = newSQLStateRegistry()
+
+func newSQLStateRegistry() *sqlStateRegistry {
+	registry := &sqlStateRegistry{
+		RWMutex: sync.RWMutex{},
+		entries: []sqlStateEntry{},
+	}
+
+	// The generic sentinel errors are registered first
+	// because the errors registered later take precedence.
+
+	entries := []sqlStateEntry{
+		// go-sqlparser
+		{sqlerrors.ErrNotImplemented, sqlerrors.FeatureNotSupported},
+		{sqlerrors.ErrNotSupported, sqlerrors.FeatureNotSupported},
+		{sqlerrors.ErrNotExist, sqlerrors.UndefinedObject},
+		{sqlerrors.ErrNotFound, sqlerrors.UndefinedObject},
+		{sqlerrors.ErrExist, sqlerrors.DuplicateObject},
+		{sqlerrors.ErrInvalid, sqlerrors.InvalidParameterValue},
+		{query.ErrNotSupported, sqlerrors.FeatureNotSupported},
+		{query.ErrInvalid, sqlerrors.InvalidParameterValue},
+		{fn.ErrNotImplemented, sqlerrors.FeatureNotSupported},
+		{fn.ErrNotSupported, sqlerrors.FeatureNotSupported},
+		{fn.ErrNotFound, sqlerrors.UndefinedFunction},
+		{fn.ErrInvalid, sqlerrors.InvalidParameterValue},
+		// context
+		{context.Canceled, sqlerrors.QueryCanceled},
+		// go-postgresql
+		{ErrNotImplemented, sqlerrors.FeatureNotSupported},
+		{ErrNotSupported, sqlerrors.FeatureNotSupported},
+		{ErrNotExist, sqlerrors.UndefinedObject},
+		{ErrNotFound, sqlerrors.UndefinedObject},
+		{ErrExist, sqlerrors.DuplicateObject},
+		{ErrInvalid, sqlerrors.InvalidParameterValue},
+		{ErrSyntax, sqlerrors.SyntaxError},
+		{ErrNoTable, sqlerrors.SyntaxError},
+		{ErrDatabaseNotExist, sqlerrors.InvalidCatalogName},
+		{ErrTableNotExist, sqlerrors.UndefinedTable},
+		{ErrColumnNotExist, sqlerrors.UndefinedColumn},
+		{ErrDatabaseExist, sqlerrors.DuplicateDatabase},
+		{ErrTableExist, sqlerrors.DuplicateTable},
+		{ErrColumnsNotEqual, sqlerrors.SyntaxError},
+		{ErrPreparedStatementNotExist, sqlerrors.InvalidSQLStatementName},
+		{ErrPreparedStatementExist, sqlerrors.DuplicatePreparedStatement},
+		{ErrPreparedPortalNotExist, sqlerrors.InvalidCursorName},
+		{ErrQueryCanceled, sqlerrors.QueryCanceled},
+		{ErrInFailedTransaction, sqlerrors.InFailedSQLTransaction},
+		{ErrNoActiveTransaction, sqlerrors.NoActiveSQLTransaction},
+		{ErrReadOnlyTransaction, sqlerrors.ReadOnlySQLTransaction},
+		{ErrActiveTransaction, sqlerrors.ActiveSQLTransaction},
+		{ErrPreparedTransactionNotExist, sqlerrors.UndefinedObject},
+		{ErrPreparedTransactionExist, sqlerrors.DuplicateObject},
+		{ErrSavepointNotExist, sqlerrors.InvalidSavepointSpecification},
+	}
+	for _, entry := range entries {
+		registry.Register(entry.target, entry.code)
+	}
+
+	return registry
+}
+
+// Register registers the SQLSTATE code for the specified sentinel error.
+func (registry *sqlStateRegistry) Register(target error, code Code) {
+	registry.Lock()
+	defer registry.Unlock()
+	registry.entries = append(registry.entries, sqlStateEntry{target: target, code: code})
+}
+
+// Lookup returns the SQLSTATE code of the latest registered sentinel error in the specified error tree.
+// The specific errors of go-sqlparser are looked up as the specific sentinel errors of this package.
+func (registry *sqlStateRegistry) Lookup(err error) (Code, bool) {
+	err = wrapSQLParserError(err)
+	registry.RLock()
+	defer registry.RUnlock()
+	for n := len(registry.entries) - 1; 0 <= n; n-- {
+		entry := registry.entries[n]
+		if errors.Is(err, entry.target) {
+			return entry.code, true
+		}
+	}
+	return "", false
+}
+
+// RegisterSQLState registers the SQLSTATE code for the specified sentinel error.
+// The error responses of errors which wrap the sentinel error have the code unless they are SQL errors.
+// The codes registered later take precedence over the earlier ones, so users can override the default codes.
+func RegisterSQLState(target error, code Code) {
+	sqlStates.Register(target, code)
+}
+
+// LookupSQLState returns the registered SQLSTATE code for the specified error.
+func LookupSQLState(err error) (Code, bool) {
+	return sqlStates.Lookup(err)
+}
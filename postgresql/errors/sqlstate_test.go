@@ -0,0 +1,49 @@
+// Copyright (C) 2019 The go-postgresql Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"fmt"
+	"testing"
+
+	sqlerrors "github.com/cybergarage/go-sqlparser/sql/errors"
+)
+
+func TestLookupSQLState(t *testing.T) {
+	tests := []struct {
+		err      error
+		expected Code
+	}{
+		{sqlerrors.NewErrDatabaseNotExist("db"), sqlerrors.InvalidCatalogName},
+		{sqlerrors.NewErrTableNotExist("tbl"), sqlerrors.UndefinedTable},
+		{sqlerrors.NewErrDatabaseExist("db"), sqlerrors.DuplicateDatabase},
+		{sqlerrors.NewErrTableExist("tbl"), sqlerrors.DuplicateTable},
+		{sqlerrors.NewErrColumnNotExist("col"), sqlerrors.UndefinedColumn},
+		{sqlerrors.NewErrColumnValueNotExist("col"), sqlerrors.UndefinedObject},
+		{sqlerrors.NewErrPreparedStatementNotExist("stmt"), sqlerrors.InvalidSQLStatementName},
+		{sqlerrors.NewErrPreparedPortalNotExist("portal"), sqlerrors.InvalidCursorName},
+		{fmt.Errorf("obj is %w", sqlerrors.ErrNotExist), sqlerrors.UndefinedObject},
+		{fmt.Errorf("select : %w", sqlerrors.NewErrTableNotExist("tbl")), sqlerrors.UndefinedTable},
+		{NewErrTableNotExist("tbl"), sqlerrors.UndefinedTable},
+		{NewErrDatabaseExist("db"), sqlerrors.DuplicateDatabase},
+	}
+
+	for _, test := range tests {
+		code, ok := LookupSQLState(test.err)
+		if !ok || code != test.expected {
+			t.Errorf("%s: %s != %s", test.err, code, test.expected)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright (C) 2019 The go-postgresql Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"errors"
+	"fmt"
+
+	sqlerrors "github.com/cybergarage/go-sqlparser/sql/errors"
+)
+
+// ErrInFailedTransaction is returned when a statement is executed in an aborted transaction block.
+var ErrInFailedTransaction = errors.New("current transaction is aborted, commands ignored until end of transaction block")
+
+// ErrNoActiveTransaction is returned when a statement which can only be used in transaction blocks is executed outside of them.
+var ErrNoActiveTransaction = errors.New("no transaction in progress")
+
+// ErrActiveTransaction is returned when a statement which cannot run inside transaction blocks is executed in them.
+var ErrActiveTransaction = errors.New("transaction in progress")
+
+// ErrPreparedTransactionNotExist is returned when the specified prepared transaction does not exist.
+var ErrPreparedTransactionNotExist = fmt.Errorf("%w", ErrNotExist)
+
+// ErrPreparedTransactionExist is returned when the specified transaction identifier is already in use.
+var ErrPreparedTransactionExist = fmt.Errorf("%w", ErrExist)
+
+// ErrReadOnlyTransaction is returned when a statement which modifies data is executed in a read-only transaction.
+var ErrReadOnlyTransaction = errors.New("read-only transaction")
+
+// ErrSavepointNotExist is returned when the specified savepoint does not exist.
+var ErrSavepointNotExist = fmt.Errorf("%w", ErrNotExist)
+
+// NewErrNoActiveTransaction returns a new error for the specified statement outside of transaction blocks.
+func NewErrNoActiveTransaction(v string) error {
+	return fmt.Errorf("%v can only be used in transaction blocks : %w", v, ErrNoActiveTransaction)
+}
+
+// NewErrActiveTransaction returns a new error for the specified statement inside of transaction blocks.
+func NewErrActiveTransaction(v string) error {
+	return fmt.Errorf("%v cannot run inside a transaction block : %w", v, ErrActiveTransaction)
+}
+
+// NewErrPreparedTransactionNotExist returns a new prepared transaction not exist error.
+func NewErrPreparedTransactionNotExist(v string) error {
+	return fmt.Errorf("prepared transaction (%v) is %w", v, ErrPreparedTransactionNotExist)
+}
+
+// NewErrPreparedTransactionExist returns a new prepared transaction exist error.
+func NewErrPreparedTransactionExist(v string) error {
+	return fmt.Errorf("prepared transaction (%v) is %w", v, ErrPreparedTransactionExist)
+}
+
+// NewErrReadOnlyTransaction returns a new error for the specified statement in a read-only transaction.
+func NewErrReadOnlyTransaction(v string) error {
+	return fmt.Errorf("cannot execute %v in a %w", v, ErrReadOnlyTransaction)
+}
+
+// NewErrSavepointNotExist returns a new savepoint not exist error.
+func NewErrSavepointNotExist(v string) error {
+	return fmt.Errorf("savepoint (%v) is %w", v, ErrSavepointNotExist)
+}
+
+// NewErrInFailedTransaction returns a new error for the statements in an aborted transaction block.
+func NewErrInFailedTransaction() error {
+	return ErrInFailedTransaction
+}
+
+// NewWarningActiveTransaction returns a new warning for BEGIN in a transaction block.
+func NewWarningActiveTransaction() *SQLError {
+	return NewWarning(sqlerrors.ActiveSQLTransaction, "there is already a transaction in progress")
+}
+
+// NewWarningNoActiveTransaction returns a new warning for COMMIT or ROLLBACK outside of a transaction block.
+func NewWarningNoActiveTransaction() *SQLError {
+	return NewWarning(sqlerrors.NoActiveSQLTransaction, "there is no transaction in progress")
+}
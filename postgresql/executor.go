@@ -65,6 +65,12 @@ type DMOExecutor interface {
 	Delete(Conn, query.Delete) (protocol.Responses, error)
 }
 
+// DMOStreamExecutor defines an optional executor interface to stream the result sets of SELECT queries row by row.
+type DMOStreamExecutor interface {
+	// SelectStream handles a SELECT query and returns the response stream of the result set.
+	SelectStream(Conn, query.Select) (*query.ResultSetStream, error)
+}
+
 // DMOExExecutor defines a executor interface for extended DMO (Data Manipulation Operations).
 type DMOExExecutor interface {
 	// Vacuum handles a VACUUM query.
@@ -96,3 +102,9 @@ type SystemDMOExecutor interface {
 	// Select handles a SELECT query.
 	SystemSelect(Conn, query.Select) (protocol.Responses, error)
 }
+
+// SystemDMOStreamExecutor defines an optional executor interface to stream the result sets of SELECT queries for system tables row by row.
+type SystemDMOStreamExecutor interface {
+	// SystemSelectStream handles a SELECT query for system tables and returns the response stream of the result set.
+	SystemSelectStream(Conn, query.Select) (*query.ResultSetStream, error)
+}
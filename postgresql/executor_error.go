@@ -17,7 +17,9 @@ package postgresql
 import (
 	"fmt"
 
+	"github.com/cybergarage/go-postgresql/postgresql/errors"
 	"github.com/cybergarage/go-postgresql/postgresql/protocol"
+	sqlerrors "github.com/cybergarage/go-sqlparser/sql/errors"
 )
 
 // nullErrorHandler represents a base error handler.
@@ -31,7 +33,7 @@ func NewNullErrorHandler() ErrorHandler {
 
 // ParserError handles a parser error.
 func (executor *nullErrorHandler) ParserError(conn Conn, q string, err error) (protocol.Responses, error) {
-	resErr := fmt.Errorf("parser error : %w", err)
+	resErr := errors.NewSQLErrorWith(sqlerrors.SyntaxError, fmt.Errorf("parser error : %w", err))
 	res, err := protocol.NewErrorResponseWith(resErr)
 	if err != nil {
 		return nil, err
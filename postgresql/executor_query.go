@@ -50,7 +50,7 @@ func (executor *defaultQueryExecutor) Begin(conn Conn, stmt query.Begin) (protoc
 		return nil, errors.NewErrNotImplemented("BEGIN")
 	}
 
-	err := executor.sqlExecutor.Commit(conn, stmt)
+	err := executor.sqlExecutor.Begin(conn, stmt)
 	if err != nil {
 		return nil, err
 	}
@@ -187,6 +187,20 @@ func (executor *defaultQueryExecutor) Select(conn Conn, stmt query.Select) (prot
 	return query.NewResponseFromResultSet(rs)
 }
 
+// SelectStream handles a SELECT query and returns the response stream of the result set.
+func (executor *defaultQueryExecutor) SelectStream(conn Conn, stmt query.Select) (*query.ResultSetStream, error) {
+	if executor.sqlExecutor == nil {
+		return nil, errors.NewErrNotImplemented("SELECT")
+	}
+
+	rs, err := executor.sqlExecutor.Select(conn, stmt)
+	if err != nil {
+		return nil, err
+	}
+
+	return query.NewResultSetStreamWith(rs)
+}
+
 // Update handles a UPDATE query.
 func (executor *defaultQueryExecutor) Update(conn Conn, stmt query.Update) (protocol.Responses, error) {
 	if executor.sqlExecutor == nil {
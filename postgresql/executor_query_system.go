@@ -62,3 +62,17 @@ func (executor *defaultSystemQueryExecutor) SystemSelect(conn Conn, stmt query.S
 
 	return query.NewResponseFromResultSet(rs)
 }
+
+// SystemSelectStream handles a SELECT query for system tables and returns the response stream of the result set.
+func (executor *defaultSystemQueryExecutor) SystemSelectStream(conn Conn, stmt query.Select) (*query.ResultSetStream, error) {
+	if executor.sqlExecutor == nil {
+		return nil, errors.NewErrNotImplemented("SELECT")
+	}
+
+	rs, err := executor.sqlExecutor.SystemSelect(conn, stmt)
+	if err != nil {
+		return nil, err
+	}
+
+	return query.NewResultSetStreamWith(rs)
+}
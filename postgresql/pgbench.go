@@ -15,6 +15,8 @@
 package postgresql
 
 import (
+	"strings"
+
 	"github.com/cybergarage/go-postgresql/postgresql/protocol"
 	"github.com/cybergarage/go-postgresql/postgresql/system"
 )
@@ -25,7 +27,7 @@ var (
 
 // IsMatchQuery returns true if the query is matched with the prefix.
 func IsMatchQuery(q string, prefix string) bool {
-	return q[:len(prefix)] == prefix
+	return strings.HasPrefix(q, prefix)
 }
 
 // IsPgbenchGetPartitionQuery returns true if the query is pgbenchGetPartitionQuery.
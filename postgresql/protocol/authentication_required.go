@@ -27,3 +27,39 @@ func NewAuthenticationMD5Password(salt []byte) (*ResponseMessage, error) {
 	msg.AppendBytes(salt)
 	return msg, nil
 }
+
+// NewAuthenticationSASL returns a new AuthenticationSASL protocol with the specified mechanisms.
+func NewAuthenticationSASL(mechs ...string) (*ResponseMessage, error) {
+	msg := NewResponseMessageWith(AuthenticationSASLMessage)
+	err := msg.AppendInt32(AuthenticationSASLRequired)
+	if err != nil {
+		return nil, err
+	}
+	for _, mech := range mechs {
+		err = msg.AppendString(mech)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return msg, msg.AppendTerminator()
+}
+
+// NewAuthenticationSASLContinue returns a new AuthenticationSASLContinue protocol with the specified SASL data.
+func NewAuthenticationSASLContinue(data []byte) (*ResponseMessage, error) {
+	msg := NewResponseMessageWith(AuthenticationSASLContinueMessage)
+	err := msg.AppendInt32(AuthenticationSASLContinueRequired)
+	if err != nil {
+		return nil, err
+	}
+	return msg, msg.AppendBytes(data)
+}
+
+// NewAuthenticationSASLFinal returns a new AuthenticationSASLFinal protocol with the specified SASL outcome data.
+func NewAuthenticationSASLFinal(data []byte) (*ResponseMessage, error) {
+	msg := NewResponseMessageWith(AuthenticationSASLFinalMessage)
+	err := msg.AppendInt32(AuthenticationSASLFinalRequired)
+	if err != nil {
+		return nil, err
+	}
+	return msg, msg.AppendBytes(data)
+}
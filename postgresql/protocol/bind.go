@@ -31,6 +31,7 @@ const (
 // BindParam represents a bind parameter.
 type BindParam struct {
 	FormatCode int16
+	ObjectID   ObjectID
 	Value      any
 }
 
@@ -59,6 +60,18 @@ type Bind struct {
 	PortalName    string
 	StatementName string
 	Params        BindParams
+	ResultFormats FormatCodes
+}
+
+// NewBindWith returns a new bind message with the specified portal name, statement name and text parameters.
+func NewBindWith(portal string, stmt string, params BindParams) *Bind {
+	return &Bind{
+		RequestMessage: nil,
+		PortalName:     portal,
+		StatementName:  stmt,
+		Params:         params,
+		ResultFormats:  FormatCodes{},
+	}
 }
 
 // NewBindWithReader returns a new bind protocol.
@@ -88,7 +101,7 @@ func NewBindWithReader(reader *MessageReader) (*Bind, error) {
 	}
 
 	// The parameter format codes. Each must presently be zero (text) or one (binary).
-	paramFmts := make([]int16, paramFmtNum)
+	paramFmts := make(FormatCodes, paramFmtNum)
 	for n := range paramFmtNum {
 		fmt, err := reader.ReadInt16()
 		if err != nil {
@@ -141,7 +154,7 @@ func NewBindWithReader(reader *MessageReader) (*Bind, error) {
 	}
 
 	// The result-column format codes. Each must presently be zero (text) or one (binary).
-	resFmts := make([]int16, resFmtNum)
+	resFmts := make(FormatCodes, resFmtNum)
 	for n := range resFmtNum {
 		fmt, err := reader.ReadInt16()
 		if err != nil {
@@ -152,23 +165,21 @@ func NewBindWithReader(reader *MessageReader) (*Bind, error) {
 
 	params := make([]*BindParam, paramValNum)
 	for n := range paramValNum {
-		paramFmt := TextFormat
-		if int(n) < len(paramFmts) {
-			paramFmt = paramFmts[n]
-		}
-		paramValBytes := []byte{}
-		if int(n) < len(paramBytes) {
-			paramValBytes = paramBytes[n]
-		}
+		paramFmt := paramFmts.FormatCode(int(n))
+		paramValBytes := paramBytes[n]
+		// The binary values are decoded with the parameter data types of the prepared statement.
 		var paramVal any
-		switch paramFmt {
-		case TextFormat:
-			paramVal = string(paramValBytes)
-		case BinaryFormat:
+		switch {
+		case paramValBytes == nil:
+			paramVal = nil
+		case paramFmt == BinaryFormat:
 			paramVal = paramValBytes
+		default:
+			paramVal = string(paramValBytes)
 		}
 		params[n] = &BindParam{
 			FormatCode: paramFmt,
+			ObjectID:   0,
 			Value:      paramVal,
 		}
 	}
@@ -178,5 +189,6 @@ func NewBindWithReader(reader *MessageReader) (*Bind, error) {
 		PortalName:     portal,
 		StatementName:  stmt,
 		Params:         params,
+		ResultFormats:  resFmts,
 	}, nil
 }
@@ -0,0 +1,211 @@
+// Copyright (C) 2019 The go-postgresql Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package protocol
+
+import (
+	"encoding/binary"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cybergarage/go-postgresql/postgresql/system"
+	"github.com/google/uuid"
+)
+
+// PostgreSQL: src/backend/utils/adt (*recv functions)
+// https://github.com/postgres/postgres/tree/master/src/backend/utils/adt
+
+const (
+	jsonbBinaryVersion = 1
+)
+
+// Decode decodes the binary parameters with the specified parameter data types.
+// The parameters whose data types are not specified keep the raw bytes.
+func (params BindParams) Decode(oids []ObjectID) error {
+	for n, param := range params {
+		if n < len(oids) {
+			param.ObjectID = oids[n]
+		}
+		if err := param.Decode(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Decode decodes the binary value of the parameter with the data type of the parameter.
+func (param *BindParam) Decode() error {
+	if param.FormatCode != BinaryFormat {
+		return nil
+	}
+	b, ok := param.Value.([]byte)
+	if !ok || b == nil {
+		return nil
+	}
+	v, err := decodeBinaryValue(param.ObjectID, b)
+	if err != nil {
+		return err
+	}
+	param.Value = v
+	return nil
+}
+
+// decodeBinaryValue returns the value of the specified binary representation of the data type.
+func decodeBinaryValue(oid ObjectID, b []byte) (any, error) { // nolint:gocyclo
+	expectLen := func(n int) error {
+		if len(b) != n {
+			return newInvalidLengthError(len(b))
+		}
+		return nil
+	}
+
+	switch oid { // nolint:exhaustive
+	case system.Bool:
+		if err := expectLen(1); err != nil {
+			return nil, err
+		}
+		return b[0] != 0, nil
+	case system.Int2:
+		if err := expectLen(2); err != nil {
+			return nil, err
+		}
+		return int64(int16(binary.BigEndian.Uint16(b))), nil
+	case system.Int4:
+		if err := expectLen(4); err != nil {
+			return nil, err
+		}
+		return int64(int32(binary.BigEndian.Uint32(b))), nil
+	case system.Int8:
+		if err := expectLen(8); err != nil {
+			return nil, err
+		}
+		return int64(binary.BigEndian.Uint64(b)), nil
+	case system.Float4:
+		if err := expectLen(4); err != nil {
+			return nil, err
+		}
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(b))), nil
+	case system.Float8:
+		if err := expectLen(8); err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(b)), nil
+	case system.Text, system.Varchar, system.Bpchar, system.Name, system.Char, system.JSON, system.XML:
+		return string(b), nil
+	case system.JSONb:
+		if len(b) < 1 || b[0] != jsonbBinaryVersion {
+			return nil, newErrBinaryFormatNotSupported(oid, b)
+		}
+		return string(b[1:]), nil
+	case system.Bytea:
+		return b, nil
+	case system.Timestamp, system.Timestamptz:
+		if err := expectLen(8); err != nil {
+			return nil, err
+		}
+		// The microseconds are split into the seconds and the rest because time.Duration overflows about 292 years after the epoch.
+		us := int64(binary.BigEndian.Uint64(b))
+		return time.Unix(postgresEpoch.Unix()+us/1_000_000, (us%1_000_000)*1000).UTC(), nil
+	case system.Date:
+		if err := expectLen(4); err != nil {
+			return nil, err
+		}
+		days := int32(binary.BigEndian.Uint32(b))
+		return postgresEpoch.AddDate(0, 0, int(days)), nil
+	case system.UUID:
+		id, err := uuid.FromBytes(b)
+		if err != nil {
+			return nil, err
+		}
+		return id.String(), nil
+	case system.Numeric:
+		// The decimal string keeps the precision of the numeric value.
+		return decodeBinaryNumeric(b)
+	}
+
+	// The parameters of the unknown data types keep the raw bytes.
+	return b, nil
+}
+
+// decodeBinaryNumeric returns the decimal string of the specified binary numeric value,
+// which has the number of digits, the weight, the sign, the display scale, and the base 10000 digits.
+func decodeBinaryNumeric(b []byte) (string, error) {
+	if len(b) < 8 {
+		return "", newInvalidLengthError(len(b))
+	}
+	ndigits := int(binary.BigEndian.Uint16(b[0:]))
+	weight := int(int16(binary.BigEndian.Uint16(b[2:])))
+	sign := int(binary.BigEndian.Uint16(b[4:]))
+	dscale := int(binary.BigEndian.Uint16(b[6:]))
+	if len(b) != 8+ndigits*2 {
+		return "", newInvalidLengthError(len(b))
+	}
+	switch sign {
+	case numericPositive, numericNegative:
+	case numericNaN:
+		return "NaN", nil
+	case numericPInf:
+		return "Infinity", nil
+	case numericNInf:
+		return "-Infinity", nil
+	default:
+		return "", newErrInvalidNumericSign(sign)
+	}
+
+	digits := make([]int, ndigits)
+	for n := range ndigits {
+		digits[n] = int(binary.BigEndian.Uint16(b[8+n*2:]))
+	}
+	digitAt := func(n int) int {
+		if n < 0 || len(digits) <= n {
+			return 0
+		}
+		return digits[n]
+	}
+
+	var s strings.Builder
+	if sign == numericNegative {
+		s.WriteString("-")
+	}
+	// The integer part has the digits from the weight to zero.
+	if weight < 0 {
+		s.WriteString("0")
+	} else {
+		for n := 0; n <= weight; n++ {
+			if n == 0 {
+				s.WriteString(strconv.Itoa(digitAt(n)))
+				continue
+			}
+			s.WriteString(leftPadDigit(digitAt(n)))
+		}
+	}
+	// The fractional part has the digits after the weight up to the display scale.
+	if 0 < dscale {
+		var frac strings.Builder
+		for n := weight + 1; frac.Len() < dscale; n++ {
+			frac.WriteString(leftPadDigit(digitAt(n)))
+		}
+		s.WriteString(".")
+		s.WriteString(frac.String()[:dscale])
+	}
+	return s.String(), nil
+}
+
+// leftPadDigit returns the four decimal digits of the specified base 10000 digit.
+func leftPadDigit(digit int) string {
+	s := strconv.Itoa(digit)
+	return strings.Repeat("0", numericBaseLen-len(s)) + s
+}
@@ -0,0 +1,78 @@
+// Copyright (C) 2019 The go-postgresql Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package protocol
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/cybergarage/go-postgresql/postgresql/system"
+)
+
+func TestBindParamDecode(t *testing.T) {
+	tests := []struct {
+		oid      ObjectID
+		b        []byte
+		expected any
+	}{
+		{system.Bool, []byte{0x01}, true},
+		{system.Int2, []byte{0xFF, 0xFE}, int64(-2)},
+		{system.Int4, []byte{0x00, 0x00, 0x01, 0x02}, int64(258)},
+		{system.Int8, []byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x03}, int64(3)},
+		{system.Float8, []byte{0x3F, 0xF8, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}, 1.5},
+		{system.Text, []byte("abc"), "abc"},
+		{system.JSONb, []byte{0x01, '{', '}'}, "{}"},
+		{system.Bytea, []byte{0x00, 0x01}, []byte{0x00, 0x01}},
+		{system.Timestamp, []byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x0F, 0x42, 0x40}, time.Date(2000, 1, 1, 0, 0, 1, 0, time.UTC)},
+		// The timestamps out of the time.Duration range, about 292 years from the PostgreSQL epoch.
+		{system.Timestamp, []byte{0x03, 0x80, 0xE7, 0x0B, 0x91, 0x3B, 0x7F, 0xFF}, time.Date(9999, 12, 31, 23, 59, 59, 999999000, time.UTC)},
+		{system.Timestamptz, []byte{0xFF, 0xD3, 0x27, 0xA5, 0xD2, 0x5E, 0x41, 0x20}, time.Date(1600, 1, 1, 0, 0, 0, 500000000, time.UTC)},
+		{system.Date, []byte{0xFF, 0xFF, 0xFF, 0xFF}, time.Date(1999, 12, 31, 0, 0, 0, 0, time.UTC)},
+		{system.UUID, []byte{0x12, 0x34, 0x56, 0x78, 0x12, 0x34, 0x56, 0x78, 0x12, 0x34, 0x56, 0x78, 0x12, 0x34, 0x56, 0x78}, "12345678-1234-5678-1234-567812345678"},
+		{system.Numeric, []byte{0x00, 0x02, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0x00, 0x0C, 0x0D, 0x48}, "12.34"},
+		{system.Numeric, []byte{0x00, 0x01, 0xFF, 0xFF, 0x40, 0x00, 0x00, 0x04, 0x00, 0x05}, "-0.0005"},
+		{system.Numeric, []byte{0x00, 0x00, 0x00, 0x00, 0xC0, 0x00, 0x00, 0x00}, "NaN"},
+		{system.Numeric, []byte{0x00, 0x00, 0x00, 0x00, 0xD0, 0x00, 0x00, 0x00}, "Infinity"},
+		{system.Numeric, []byte{0x00, 0x00, 0x00, 0x00, 0xF0, 0x00, 0x00, 0x00}, "-Infinity"},
+		{system.Numeric, []byte{0x00, 0x08, 0x00, 0x04, 0x00, 0x00, 0x00, 0x0A, 0x04, 0xD2, 0x16, 0x2E, 0x23, 0x34, 0x0D, 0x80, 0x1E, 0xD2, 0x00, 0x7B, 0x11, 0xD7, 0x22, 0xC4}, "12345678901234567890.0123456789"},
+		{0, []byte{0x01}, []byte{0x01}},
+	}
+
+	for _, test := range tests {
+		param := &BindParam{FormatCode: BinaryFormat, ObjectID: test.oid, Value: test.b}
+		if err := param.Decode(); err != nil {
+			t.Error(err)
+			continue
+		}
+		if fmt.Sprintf("%T %v", param.Value, param.Value) != fmt.Sprintf("%T %v", test.expected, test.expected) {
+			t.Errorf("%d: Expected %T %v, but got %T %v", test.oid, test.expected, test.expected, param.Value, param.Value)
+		}
+	}
+
+	// The binary values must have the length of the data type.
+
+	param := &BindParam{FormatCode: BinaryFormat, ObjectID: system.Int4, Value: []byte{0x01}}
+	if err := param.Decode(); err == nil {
+		t.Errorf("%v: Expected an error", param.Value)
+	}
+
+	// The binary numeric values must have the known sign.
+
+	param = &BindParam{FormatCode: BinaryFormat, ObjectID: system.Numeric, Value: []byte{0x00, 0x00, 0x00, 0x00, 0x80, 0x00, 0x00, 0x00}}
+	if err := param.Decode(); err == nil {
+		t.Errorf("%v: Expected an error", param.Value)
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright (C) 2019 The go-postgresql Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package protocol
+
+import (
+	util "github.com/cybergarage/go-postgresql/postgresql/encoding/bytes"
+)
+
+// PostgreSQL: Documentation: 16: 55.2. Message Flow
+// https://www.postgresql.org/docs/16/protocol-flow.html
+// PostgreSQL: Documentation: 16: 55.7. Message Formats
+// https://www.postgresql.org/docs/16/protocol-message-formats.html
+
+const (
+	// CancelRequestCode represents a CancelRequest message code.
+	CancelRequestCode = 80877102
+	// CancelRequestLength represents a CancelRequest message length.
+	CancelRequestLength = 16
+)
+
+// CancelRequest represents a CancelRequest protocol.
+type CancelRequest struct {
+	RequestCode int32
+	ProcessID   int32
+	SecretKey   int32
+}
+
+// IsCancelRequest returns true if the next message is a CancelRequest message.
+func IsCancelRequest(reader *MessageReader) (bool, error) {
+	b, err := reader.PeekBytes(8)
+	if err != nil {
+		return false, err
+	}
+	return util.BytesToInt32(b[0:4]) == CancelRequestLength && util.BytesToInt32(b[4:8]) == CancelRequestCode, nil
+}
+
+// NewCancelRequestWithReader returns a new CancelRequest message with the specified reader.
+func NewCancelRequestWithReader(reader *MessageReader) (*CancelRequest, error) {
+	_, err := reader.ReadInt32()
+	if err != nil {
+		return nil, err
+	}
+	code, err := reader.ReadInt32()
+	if err != nil {
+		return nil, err
+	}
+	if code != CancelRequestCode {
+		return nil, newErrInvalidCancelRequestCode(code)
+	}
+	pid, err := reader.ReadInt32()
+	if err != nil {
+		return nil, err
+	}
+	key, err := reader.ReadInt32()
+	if err != nil {
+		return nil, err
+	}
+	return &CancelRequest{
+		RequestCode: code,
+		ProcessID:   pid,
+		SecretKey:   key,
+	}, nil
+}
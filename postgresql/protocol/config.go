@@ -16,6 +16,7 @@ package protocol
 
 import (
 	"github.com/cybergarage/go-authenticator/auth/tls"
+	"github.com/cybergarage/go-postgresql/postgresql/auth"
 )
 
 // Config represents a server configuration.
@@ -42,4 +43,23 @@ type Config interface {
 	Address() string
 	// Port returns a listen port from the configuration.
 	Port() int
+	// SetAuthMethod sets a client authentication method to the configuration.
+	// The default method is password, and the md5 and scram-sha-256 methods require a credential store.
+	SetAuthMethod(m auth.Method)
+	// AuthMethod returns the client authentication method from the configuration.
+	AuthMethod() auth.Method
+	// SetHBA sets host-based authentication rules to the configuration.
+	SetHBA(hba *auth.HBA)
+	// HBA returns the host-based authentication rules from the configuration.
+	HBA() *auth.HBA
+	// SetIdentMap sets user name maps to the configuration.
+	// The maps are referred by the map option of the host-based authentication rules,
+	// and the default map is used by the certificate authentication without the map option.
+	SetIdentMap(m *auth.IdentMap)
+	// IdentMap returns the user name maps from the configuration.
+	IdentMap() *auth.IdentMap
+	// SetWriteBufferSize sets a size of the response buffer of connections to the configuration.
+	SetWriteBufferSize(size int)
+	// WriteBufferSize returns the size of the response buffer of connections from the configuration.
+	WriteBufferSize() int
 }
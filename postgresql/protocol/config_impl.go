@@ -16,12 +16,15 @@ package protocol
 
 import (
 	"github.com/cybergarage/go-authenticator/auth/tls"
+	"github.com/cybergarage/go-postgresql/postgresql/auth"
 )
 
 const (
 	defaultAddr          = ""
 	defaultPort          = 5432
 	defaultServerVersion = "16.0"
+	defaultAuthMethod    = auth.MethodPassword
+	defaultWriteBuffSize = DefaultWriteBufferSize
 )
 
 // config stores server configuration parammeters.
@@ -31,6 +34,10 @@ type config struct {
 	serverVersion  string
 	addr           string
 	port           int
+	authMethod     auth.Method
+	hba            *auth.HBA
+	identMap       *auth.IdentMap
+	writeBufSize   int
 	tls.CertConfig
 }
 
@@ -42,6 +49,10 @@ func NewDefaultConfig() Config {
 		serverVersion:  defaultServerVersion,
 		addr:           defaultAddr,
 		port:           defaultPort,
+		authMethod:     defaultAuthMethod,
+		hba:            nil,
+		identMap:       nil,
+		writeBufSize:   defaultWriteBuffSize,
 		CertConfig:     tls.NewCertConfig(),
 	}
 	return config
@@ -96,3 +107,47 @@ func (config *config) SetServerVersion(v string) {
 func (config *config) ServerVersion() string {
 	return config.serverVersion
 }
+
+// SetAuthMethod sets a client authentication method to the configuration.
+func (config *config) SetAuthMethod(m auth.Method) {
+	config.authMethod = m
+}
+
+// AuthMethod returns the client authentication method from the configuration.
+func (config *config) AuthMethod() auth.Method {
+	return config.authMethod
+}
+
+// SetHBA sets host-based authentication rules to the configuration.
+// The authentication method of the matched rule is used instead of the configured method.
+func (config *config) SetHBA(hba *auth.HBA) {
+	config.hba = hba
+}
+
+// HBA returns the host-based authentication rules from the configuration.
+func (config *config) HBA() *auth.HBA {
+	return config.hba
+}
+
+// SetIdentMap sets user name maps to the configuration.
+// The maps are referred by the map option of the host-based authentication rules,
+// and the default map is used by the certificate authentication without the map option.
+func (config *config) SetIdentMap(m *auth.IdentMap) {
+	config.identMap = m
+}
+
+// IdentMap returns the user name maps from the configuration.
+func (config *config) IdentMap() *auth.IdentMap {
+	return config.identMap
+}
+
+// SetWriteBufferSize sets a size of the response buffer of connections to the configuration.
+// The buffered responses are delivered when the buffer is full, or when the backend waits for the client.
+func (config *config) SetWriteBufferSize(size int) {
+	config.writeBufSize = size
+}
+
+// WriteBufferSize returns the size of the response buffer of connections from the configuration.
+func (config *config) WriteBufferSize() int {
+	return config.writeBufSize
+}
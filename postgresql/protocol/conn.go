@@ -16,7 +16,9 @@ package protocol
 
 import (
 	"crypto/tls"
+	"crypto/x509"
 
+	"github.com/cybergarage/go-postgresql/postgresql/errors"
 	"github.com/cybergarage/go-postgresql/postgresql/net"
 )
 
@@ -33,6 +35,10 @@ type MessageConn interface {
 	ResponseMessages(resMsgs Responses) error
 	// ResponseError sends an error response.
 	ResponseError(err error) error
+	// ResponseNotice sends a notice response.
+	ResponseNotice(notice *errors.SQLError) error
+	// Flush delivers the buffered responses to the client.
+	Flush() error
 	// SkipMessage skips a
 	SkipMessage() error
 	// ReadyForMessage sends a ready for
@@ -45,16 +51,34 @@ type TLSConn interface {
 	IsTLSConnection() bool
 	// TLSConn returns a TLS connection.
 	TLSConn() *tls.Conn
+	// TLSServerCertificate returns the server certificate presented in the TLS handshake.
+	TLSServerCertificate() (*x509.Certificate, bool)
 }
 
 // TransactionConn represents a transaction connection.
 type TransactionConn interface {
-	// IsTransactionLocked returns true if the transaction is locked.
-	LockTransaction() error
-	// UnlockTransaction unlocks the transaction.
-	UnlockTransaction() error
+	// SetTransactionState sets the transaction state.
+	SetTransactionState(TransactionState)
+	// TransactionState returns the transaction state.
+	TransactionState() TransactionState
 	// TransactionStatus returns the transaction status.
 	TransactionStatus() TransactionStatus
+	// Savepoints returns the savepoints of the current transaction block.
+	Savepoints() *Savepoints
+	// SetTransactionOptions sets the characteristics of the current transaction block.
+	SetTransactionOptions(TransactionOptions)
+	// TransactionOptions returns the characteristics of the current transaction block.
+	TransactionOptions() TransactionOptions
+}
+
+// CancelConn represents a connection which can be canceled by a CancelRequest.
+type CancelConn interface {
+	// ProcessID returns the process ID of the backend key data.
+	ProcessID() int32
+	// SecretKey returns the secret key of the backend key data.
+	SecretKey() int32
+	// Cancel cancels the context of the running query.
+	Cancel()
 }
 
 // Conn represents a connection.
@@ -63,4 +87,5 @@ type Conn interface {
 	MessageConn
 	TLSConn
 	TransactionConn
+	CancelConn
 }
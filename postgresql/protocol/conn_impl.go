@@ -15,18 +15,24 @@
 package protocol
 
 import (
+	"bufio"
 	"context"
 	"crypto/tls"
+	"crypto/x509"
 	"net"
 	"slices"
 	"sync"
 	"time"
 
+	"github.com/cybergarage/go-postgresql/postgresql/errors"
 	"github.com/cybergarage/go-postgresql/postgresql/system"
 	"github.com/cybergarage/go-tracing/tracer"
 	"github.com/google/uuid"
 )
 
+// DefaultWriteBufferSize is the default size of the response buffer of connections.
+const DefaultWriteBufferSize = 8192
+
 // connOption represents a connection option.
 type connOption = func(*conn)
 
@@ -36,6 +42,8 @@ type conn struct {
 
 	isClosed      bool
 	msgReader     *MessageReader
+	writer        *bufio.Writer
+	writerSize    int
 	db            string
 	schemas       []string
 	user          string
@@ -44,7 +52,16 @@ type conn struct {
 	id            ConnID
 	tracerContext tracer.Context
 	tlsConn       *tls.Conn
-	txMutex       sync.Mutex
+	tlsCert       *x509.Certificate
+	txState       TransactionState
+	savepoints    *Savepoints
+	txOpts        TransactionOptions
+	processID     int32
+	secretKey     int32
+	ctx           context.Context
+	cancel        context.CancelFunc
+	ctxMutex      sync.Mutex
+	msgState      MessageState
 }
 
 // NewConnWith returns a connection with a raw connection.
@@ -53,6 +70,8 @@ func NewConnWith(netConn net.Conn, opts ...connOption) *conn {
 		Conn:          netConn,
 		isClosed:      false,
 		msgReader:     NewMessageReaderWith(WithMessageReadeConn(netConn)),
+		writer:        nil,
+		writerSize:    DefaultWriteBufferSize,
 		db:            "",
 		schemas:       []string{},
 		user:          "",
@@ -61,11 +80,22 @@ func NewConnWith(netConn net.Conn, opts ...connOption) *conn {
 		id:            0,
 		tracerContext: nil,
 		tlsConn:       nil,
-		txMutex:       sync.Mutex{},
+		tlsCert:       nil,
+		txState:       TransactionIdleState,
+		savepoints:    NewSavepoints(),
+		txOpts:        NewTransactionOptions(),
+		processID:     0,
+		secretKey:     0,
+		ctx:           nil,
+		cancel:        nil,
+		ctxMutex:      sync.Mutex{},
+		msgState:      MessageIdleState,
 	}
+	conn.ctx, conn.cancel = context.WithCancel(context.Background())
 	for _, opt := range opts {
 		opt(conn)
 	}
+	conn.writer = bufio.NewWriterSize(netConn, conn.writerSize)
 	return conn
 }
 
@@ -83,6 +113,16 @@ func WithConnSchemas(schemas ...string) func(*conn) {
 	}
 }
 
+// WithConnWriteBufferSize sets a size of the response buffer.
+// The buffered responses are delivered when the buffer is full or at the flush points of the message flow.
+func WithConnWriteBufferSize(size int) func(*conn) {
+	return func(conn *conn) {
+		if 0 < size {
+			conn.writerSize = size
+		}
+	}
+}
+
 // WithConnTracer sets a tracer context.
 func WithConnTracer(t tracer.Context) func(*conn) {
 	return func(conn *conn) {
@@ -97,11 +137,31 @@ func WithConnTLSConn(s *tls.Conn) func(*conn) {
 	}
 }
 
+// WithConnTLSServerCertificate sets a server certificate presented in the TLS handshake.
+func WithConnTLSServerCertificate(cert *x509.Certificate) func(*conn) {
+	return func(conn *conn) {
+		conn.tlsCert = cert
+	}
+}
+
+// WithConnBackendKeyData sets a process ID and a secret key of the backend key data.
+// The process ID is used as the connection ID too.
+func WithConnBackendKeyData(pid int32, key int32) func(*conn) {
+	return func(conn *conn) {
+		conn.processID = pid
+		conn.secretKey = key
+		conn.id = ConnID(pid)
+	}
+}
+
 // Close closes the connection.
 func (conn *conn) Close() error {
 	if conn.isClosed {
 		return nil
 	}
+	conn.Cancel()
+	// Delivers the pending responses such as a FATAL error response before closing.
+	_ = conn.Flush()
 	if err := conn.Conn.Close(); err != nil {
 		return err
 	}
@@ -160,9 +220,46 @@ func (conn *conn) ID() ConnID {
 	return conn.id
 }
 
-// Context returns the context of the connection.
+// Context returns the context of the running query which is canceled by a CancelRequest.
 func (conn *conn) Context() context.Context {
-	return context.Background()
+	conn.ctxMutex.Lock()
+	defer conn.ctxMutex.Unlock()
+	return conn.ctx
+}
+
+// ProcessID returns the process ID of the backend key data.
+func (conn *conn) ProcessID() int32 {
+	return conn.processID
+}
+
+// SecretKey returns the secret key of the backend key data.
+func (conn *conn) SecretKey() int32 {
+	return conn.secretKey
+}
+
+// Cancel cancels the context of the running query.
+func (conn *conn) Cancel() {
+	conn.ctxMutex.Lock()
+	defer conn.ctxMutex.Unlock()
+	conn.cancel()
+}
+
+// resetContext releases the context of the previous query and creates a new context for the next query.
+func (conn *conn) resetContext() {
+	conn.ctxMutex.Lock()
+	defer conn.ctxMutex.Unlock()
+	conn.cancel()
+	conn.ctx, conn.cancel = context.WithCancel(context.Background())
+}
+
+// setMessageState sets the message processing state of the connection.
+func (conn *conn) setMessageState(state MessageState) {
+	conn.msgState = state
+}
+
+// MessageState returns the message processing state of the connection.
+func (conn *conn) MessageState() MessageState {
+	return conn.msgState
 }
 
 // SetSpanContext sets the tracer span context of the connection.
@@ -200,36 +297,53 @@ func (conn *conn) TLSConn() *tls.Conn {
 	return conn.tlsConn
 }
 
+// TLSServerCertificate returns the server certificate presented in the TLS handshake.
+func (conn *conn) TLSServerCertificate() (*x509.Certificate, bool) {
+	return conn.tlsCert, conn.tlsCert != nil
+}
+
 // MessageReader returns a message reader.
 func (conn *conn) MessageReader() *MessageReader {
 	return conn.msgReader
 }
 
-// LockTransaction locks the transaction.
-func (conn *conn) LockTransaction() error {
-	if !conn.txMutex.TryLock() {
-		return ErrTransactionBlocked
+// SetTransactionState sets the transaction state.
+func (conn *conn) SetTransactionState(state TransactionState) {
+	conn.txState = state
+	// The savepoints and the characteristics are reset at the end of the transaction block.
+	if state == TransactionIdleState {
+		conn.savepoints.Clear()
+		conn.txOpts = NewTransactionOptions()
 	}
-	return nil
 }
 
-// UnlockTransaction unlocks the transaction.
-func (conn *conn) UnlockTransaction() error {
-	conn.txMutex.Unlock()
-	return nil
+// TransactionState returns the transaction state.
+func (conn *conn) TransactionState() TransactionState {
+	return conn.txState
 }
 
 // TransactionStatus returns the transaction status.
 func (conn *conn) TransactionStatus() TransactionStatus {
-	// Check if the transaction is locked
-	if conn.txMutex.TryLock() {
-		conn.txMutex.Unlock()
-		return TransactionIdle
-	}
-	return TransactionBlock
+	return conn.txState.Status()
+}
+
+// Savepoints returns the savepoints of the current transaction block.
+func (conn *conn) Savepoints() *Savepoints {
+	return conn.savepoints
+}
+
+// SetTransactionOptions sets the characteristics of the current transaction block.
+func (conn *conn) SetTransactionOptions(opts TransactionOptions) {
+	conn.txOpts = opts
+}
+
+// TransactionOptions returns the characteristics of the current transaction block.
+func (conn *conn) TransactionOptions() TransactionOptions {
+	return conn.txOpts
 }
 
-// ResponseMessage sends a response.
+// ResponseMessage writes a response to the response buffer.
+// The response is delivered immediately if the backend waits for the client after the response.
 func (conn *conn) ResponseMessage(resMsg Response) error {
 	if resMsg == nil {
 		return nil
@@ -238,12 +352,33 @@ func (conn *conn) ResponseMessage(resMsg Response) error {
 	if err != nil {
 		return err
 	}
-	if _, err := conn.Conn.Write(resBytes); err != nil {
+	if _, err := conn.writer.Write(resBytes); err != nil {
 		return err
 	}
+	// Returns the message buffer to the buffer pool because the bytes are copied into the response buffer.
+	if releaser, ok := resMsg.(interface{ Release() }); ok {
+		releaser.Release()
+	}
+	if isFlushPointResponse(resMsg.Type()) {
+		return conn.Flush()
+	}
 	return nil
 }
 
+// Flush delivers the buffered responses to the client.
+func (conn *conn) Flush() error {
+	return conn.writer.Flush()
+}
+
+// isFlushPointResponse returns true if the backend waits for the client after the response of the specified type.
+func isFlushPointResponse(t Type) bool {
+	switch t { // nolint:exhaustive
+	case ReadyForQueryMessage, CopyInResponseMessage, CopyBothResponseMessage, AuthenticationOkMessage, SSLResponseMessage:
+		return true
+	}
+	return false
+}
+
 // ResponseMessages sends response messages.
 func (conn *conn) ResponseMessages(resMsgs Responses) error {
 	if len(resMsgs) == 0 {
@@ -267,12 +402,20 @@ func (conn *conn) ResponseError(err error) error {
 	if err != nil {
 		return err
 	}
-	errBytes, err := errMsg.Bytes()
+	return conn.ResponseMessage(errMsg)
+}
+
+// ResponseNotice sends a notice response.
+// Notices can be sent while executing a query, and they are sent before the other responses of the query.
+func (conn *conn) ResponseNotice(notice *errors.SQLError) error {
+	if notice == nil {
+		return nil
+	}
+	noticeMsg, err := NewNoticeResponseWith(notice)
 	if err != nil {
 		return err
 	}
-	_, err = conn.Conn.Write(errBytes)
-	return err
+	return conn.ResponseMessage(noticeMsg)
 }
 
 // SkipMessage skips a.
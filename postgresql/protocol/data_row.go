@@ -32,7 +32,8 @@ import (
 type DataRow struct {
 	*ResponseMessage
 
-	Data []any
+	Data   []any
+	fields []*RowField
 }
 
 // NewDataRow returns a new data row message instance.
@@ -40,11 +41,18 @@ func NewDataRow() *DataRow {
 	return &DataRow{
 		ResponseMessage: NewResponseMessageWith(DataRowMessage),
 		Data:            []any{},
+		fields:          []*RowField{},
 	}
 }
 
 // AppendData appends a column value to the data row protocol.
 func (msg *DataRow) AppendData(rowField *RowField, v any) error { // nolint:gocyclo
+	if v == nil {
+		msg.Data = append(msg.Data, nil)
+		msg.fields = append(msg.fields, rowField)
+		return nil
+	}
+
 	switch rowField.ObjectID { // nolint:exhaustive
 	case system.Bool:
 		if _, ok := v.(bool); !ok {
@@ -112,111 +120,25 @@ func (msg *DataRow) AppendData(rowField *RowField, v any) error { // nolint:gocy
 		}
 	}
 
-	switch rowField.FormatCode { //nolint:exhaustive
-	case system.TextFormat:
-		switch sv := v.(type) {
-		case string:
-			// v = sv
-		case time.Time:
-			v = sv.Format(system.TimestampFormat)
-		case nil:
-			v = nil
-		default:
-			var to string
-			if err := safecast.ToString(v, &to); err != nil {
-				return err
-			}
-			v = to
-		}
-	case system.BinaryFormat:
-		switch sv := v.(type) {
-		case string:
-			v = []byte(sv)
-		}
-	}
-
 	msg.Data = append(msg.Data, v)
+	msg.fields = append(msg.fields, rowField)
 
 	return nil
 }
 
-// Bytes appends a length of the message content bytes, and returns the message bytes.
-func (msg *DataRow) Bytes() ([]byte, error) { // nolint:gocyclo
+// Bytes appends the values in the format codes of the fields, and returns the message bytes.
+func (msg *DataRow) Bytes() ([]byte, error) {
 	err := msg.AppendInt16(int16(len(msg.Data)))
 	if err != nil {
 		return nil, err
 	}
-	for _, v := range msg.Data {
-		switch v := v.(type) {
-		case []byte:
-			if err := msg.AppendInt32(int32(len(v))); err != nil {
-				return nil, err
-			}
-			if err := msg.AppendBytes(v); err != nil {
-				return nil, err
-			}
-		case string:
-			if err := msg.AppendInt32(int32(len(v))); err != nil {
-				return nil, err
-			}
-			if err := msg.AppendBytes([]byte(v)); err != nil {
-				return nil, err
-			}
-		case int8:
-			if err := msg.AppendInt32(1); err != nil {
-				return nil, err
-			}
-			if err := msg.AppendInt8(v); err != nil {
-				return nil, err
-			}
-		case int16:
-			if err := msg.AppendInt32(2); err != nil {
-				return nil, err
-			}
-			if err := msg.AppendInt16(v); err != nil {
-				return nil, err
-			}
-		case int32:
-			if err := msg.AppendInt32(4); err != nil {
-				return nil, err
-			}
-			if err := msg.AppendInt32(v); err != nil {
-				return nil, err
-			}
-		case int64:
-			if err := msg.AppendInt32(8); err != nil {
-				return nil, err
-			}
-			if err := msg.AppendInt64(v); err != nil {
-				return nil, err
-			}
-		case int:
-			if err := msg.AppendInt32(8); err != nil {
-				return nil, err
-			}
-			if err := msg.AppendInt64(int64(v)); err != nil {
-				return nil, err
-			}
-		case float32:
-			if err := msg.AppendInt32(4); err != nil {
-				return nil, err
-			}
-			if err := msg.AppendFloat32(v); err != nil {
-				return nil, err
-			}
-		case float64:
-			if err := msg.AppendInt32(8); err != nil {
-				return nil, err
-			}
-			if err := msg.AppendFloat64(v); err != nil {
-				return nil, err
-			}
-		case nil:
-			if err := msg.AppendInt32(-1); err != nil {
-				return nil, err
-			}
-		default:
-			return nil, newColumnTypeNotSuppotedError(v)
+	for n, v := range msg.Data {
+		var field *RowField
+		if n < len(msg.fields) {
+			field = msg.fields[n]
+		}
+		if err := msg.appendValue(field, v); err != nil {
+			return nil, err
 		}
 	}
 	return msg.ResponseMessage.Bytes()
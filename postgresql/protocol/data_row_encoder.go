@@ -0,0 +1,380 @@
+// Copyright (C) 2019 The go-postgresql Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package protocol
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cybergarage/go-postgresql/postgresql/system"
+	"github.com/cybergarage/go-safecast/safecast"
+	"github.com/google/uuid"
+)
+
+// PostgreSQL: Documentation: 16: 55.7. Message Formats
+// https://www.postgresql.org/docs/16/protocol-message-formats.html
+// PostgreSQL: Documentation: 16: 8.4. Binary Data Types
+// https://www.postgresql.org/docs/16/datatype-binary.html
+// PostgreSQL: src/backend/utils/adt (*send functions)
+// https://github.com/postgres/postgres/tree/master/src/backend/utils/adt
+
+const (
+	numericPositive = 0x0000
+	numericNegative = 0x4000
+	numericNaN      = 0xC000
+	numericPInf     = 0xD000
+	numericNInf     = 0xF000
+	numericBase     = 10000
+	numericBaseLen  = 4
+	// numericMaxExponent is the maximum absolute exponent of the numeric literals which PostgreSQL accepts.
+	numericMaxExponent = 1000
+)
+
+// postgresEpoch is the epoch of the binary date and timestamp values.
+var postgresEpoch = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// appendValue appends the length and the value in the format code of the specified field.
+func (msg *DataRow) appendValue(field *RowField, v any) error {
+	if v == nil {
+		return msg.AppendInt32(-1)
+	}
+	// Reserves the length and fills it after the value is appended.
+	offset := msg.Len()
+	if err := msg.AppendInt32(0); err != nil {
+		return err
+	}
+	var err error
+	if field != nil && field.FormatCode == BinaryFormat {
+		err = msg.appendBinaryValue(field.ObjectID, v)
+	} else {
+		oid := ObjectID(0)
+		if field != nil {
+			oid = field.ObjectID
+		}
+		err = msg.appendTextValue(oid, v)
+	}
+	if err != nil {
+		return err
+	}
+	valueLen := msg.Len() - offset - MessageLengthSize
+	binary.BigEndian.PutUint32(msg.Buffer.Bytes()[offset:], uint32(valueLen))
+	return nil
+}
+
+// appendTextValue appends the text representation of the specified value.
+func (msg *DataRow) appendTextValue(oid ObjectID, v any) error {
+	switch v := v.(type) {
+	case string:
+		return msg.AppendStringBytes(v)
+	case []byte:
+		if oid == system.Bytea {
+			// The hex format of bytea values.
+			if err := msg.AppendStringBytes(`\x`); err != nil {
+				return err
+			}
+			return msg.AppendStringBytes(hex.EncodeToString(v))
+		}
+		return msg.AppendBytes(v)
+	case time.Time:
+		return msg.AppendStringBytes(v.Format(system.TimestampFormat))
+	}
+	var to string
+	if err := safecast.ToString(v, &to); err != nil {
+		return err
+	}
+	return msg.AppendStringBytes(to)
+}
+
+// appendBinaryValue appends the binary representation of the specified value for the data type.
+func (msg *DataRow) appendBinaryValue(oid ObjectID, v any) error { // nolint:gocyclo
+	switch oid { // nolint:exhaustive
+	case system.Bool:
+		var to bool
+		if err := safecast.ToBool(v, &to); err != nil {
+			return err
+		}
+		if to {
+			return msg.AppendByte(1)
+		}
+		return msg.AppendByte(0)
+	case system.Int2:
+		var to int16
+		if err := safecast.ToInt16(v, &to); err != nil {
+			return err
+		}
+		return msg.AppendInt16(to)
+	case system.Int4:
+		var to int32
+		if err := safecast.ToInt32(v, &to); err != nil {
+			return err
+		}
+		return msg.AppendInt32(to)
+	case system.Int8:
+		var to int64
+		if err := safecast.ToInt64(v, &to); err != nil {
+			return err
+		}
+		return msg.AppendInt64(to)
+	case system.Float4:
+		var to float32
+		if err := safecast.ToFloat32(v, &to); err != nil {
+			return err
+		}
+		return msg.AppendFloat32(to)
+	case system.Float8:
+		var to float64
+		if err := safecast.ToFloat64(v, &to); err != nil {
+			return err
+		}
+		return msg.AppendFloat64(to)
+	case system.Timestamp, system.Timestamptz, system.Date, system.Time:
+		var to time.Time
+		if err := safecast.ToTime(v, &to); err != nil {
+			return err
+		}
+		return msg.appendBinaryTime(oid, to)
+	case system.UUID:
+		return msg.appendBinaryUUID(v)
+	case system.Numeric:
+		return msg.appendBinaryNumeric(v)
+	case system.JSONb:
+		// The binary jsonb value is the version number followed by the text representation.
+		var to string
+		if err := safecast.ToString(v, &to); err != nil {
+			return err
+		}
+		if err := msg.AppendByte(jsonbBinaryVersion); err != nil {
+			return err
+		}
+		return msg.AppendStringBytes(to)
+	}
+
+	// The binary representations of the other types are the raw bytes or the network byte order values.
+
+	switch v := v.(type) {
+	case []byte:
+		return msg.AppendBytes(v)
+	case string:
+		return msg.AppendStringBytes(v)
+	case int8:
+		return msg.AppendInt8(v)
+	case int16:
+		return msg.AppendInt16(v)
+	case int32:
+		return msg.AppendInt32(v)
+	case int64:
+		return msg.AppendInt64(v)
+	case int:
+		return msg.AppendInt64(int64(v))
+	case float32:
+		return msg.AppendFloat32(v)
+	case float64:
+		return msg.AppendFloat64(v)
+	}
+
+	return newErrBinaryFormatNotSupported(oid, v)
+}
+
+// appendBinaryTime appends the binary representation of the date and time types.
+// The timestamp is the microseconds and the date is the days since the PostgreSQL epoch (2000-01-01),
+// and the time is the microseconds since midnight.
+func (msg *DataRow) appendBinaryTime(oid ObjectID, t time.Time) error {
+	// The timestamp without time zone, the date and the time are the wall clock values.
+	wallClock := func(t time.Time) time.Time {
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+	}
+	switch oid { // nolint:exhaustive
+	case system.Timestamptz:
+		return msg.AppendInt64(postgresMicroseconds(t))
+	case system.Date:
+		return msg.AppendInt32(int32(postgresDays(wallClock(t))))
+	case system.Time:
+		wt := wallClock(t)
+		midnight := time.Date(wt.Year(), wt.Month(), wt.Day(), 0, 0, 0, 0, time.UTC)
+		return msg.AppendInt64(wt.Sub(midnight).Microseconds())
+	}
+	return msg.AppendInt64(postgresMicroseconds(wallClock(t)))
+}
+
+// postgresMicroseconds returns the microseconds of the specified time since the PostgreSQL epoch.
+// The microseconds are computed from the Unix seconds because time.Duration overflows about 292 years after the epoch.
+func postgresMicroseconds(t time.Time) int64 {
+	return (t.Unix()-postgresEpoch.Unix())*1_000_000 + int64(t.Nanosecond()/1000)
+}
+
+// postgresDays returns the days of the specified time since the PostgreSQL epoch.
+func postgresDays(t time.Time) int64 {
+	const secondsPerDay = 86400
+	days := t.Unix() / secondsPerDay
+	if t.Unix()%secondsPerDay < 0 {
+		days--
+	}
+	return days - postgresEpoch.Unix()/secondsPerDay
+}
+
+// appendBinaryUUID appends the 16 bytes of the specified UUID value.
+func (msg *DataRow) appendBinaryUUID(v any) error {
+	var id uuid.UUID
+	switch v := v.(type) {
+	case uuid.UUID:
+		id = v
+	case [16]byte:
+		id = v
+	case []byte:
+		if len(v) != len(id) {
+			return newErrBinaryFormatNotSupported(system.UUID, v)
+		}
+		copy(id[:], v)
+	default:
+		var s string
+		if err := safecast.ToString(v, &s); err != nil {
+			return err
+		}
+		var err error
+		id, err = uuid.Parse(s)
+		if err != nil {
+			return err
+		}
+	}
+	return msg.AppendBytes(id[:])
+}
+
+// appendBinaryNumeric appends the binary representation of the specified numeric value,
+// which has the number of digits, the weight, the sign, the display scale, and the base 10000 digits.
+func (msg *DataRow) appendBinaryNumeric(v any) error {
+	var s string
+	switch v := v.(type) {
+	case float32:
+		s = strconv.FormatFloat(float64(v), 'f', -1, 32)
+	case float64:
+		s = strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		if err := safecast.ToString(v, &s); err != nil {
+			return err
+		}
+	}
+	digits, weight, sign, dscale, err := newNumericDigitsFrom(s)
+	if err != nil {
+		return err
+	}
+	for _, n := range []int{len(digits), weight, sign, dscale} {
+		if err := msg.AppendInt16(int16(n)); err != nil {
+			return err
+		}
+	}
+	for _, digit := range digits {
+		if err := msg.AppendInt16(digit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newNumericDigitsFrom returns the base 10000 digits, the weight of the first digit, the sign and the display scale of the specified decimal string.
+func newNumericDigitsFrom(s string) ([]int16, int, int, int, error) {
+	s = strings.TrimSpace(s)
+	switch strings.ToLower(s) {
+	case "nan":
+		return nil, 0, numericNaN, 0, nil
+	case "infinity", "+infinity", "inf", "+inf":
+		return nil, 0, numericPInf, 0, nil
+	case "-infinity", "-inf":
+		return nil, 0, numericNInf, 0, nil
+	}
+
+	sign := numericPositive
+	switch {
+	case strings.HasPrefix(s, "-"):
+		sign = numericNegative
+		s = s[1:]
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	}
+
+	// The exponent literal is parsed as the decimal string instead of the float to keep the precision.
+	mantissa, exp := s, 0
+	if n := strings.IndexAny(s, "eE"); 0 <= n {
+		var err error
+		mantissa = s[:n]
+		exp, err = strconv.Atoi(s[n+1:])
+		if err != nil || exp < -numericMaxExponent || numericMaxExponent < exp {
+			return nil, 0, 0, 0, newErrBinaryFormatNotSupported(system.Numeric, s)
+		}
+	}
+
+	intPart, fracPart, _ := strings.Cut(mantissa, ".")
+	for _, part := range []string{intPart, fracPart} {
+		for _, c := range part {
+			if c < '0' || '9' < c {
+				return nil, 0, 0, 0, newErrBinaryFormatNotSupported(system.Numeric, s)
+			}
+		}
+	}
+	intPart, fracPart = shiftNumericPoint(intPart, fracPart, exp)
+	dscale := len(fracPart)
+
+	// Pads the integer part on the left and the fractional part on the right to the multiple of the base length.
+	if r := len(intPart) % numericBaseLen; r != 0 {
+		intPart = strings.Repeat("0", numericBaseLen-r) + intPart
+	}
+	if r := len(fracPart) % numericBaseLen; r != 0 {
+		fracPart += strings.Repeat("0", numericBaseLen-r)
+	}
+
+	allDigits := intPart + fracPart
+	digits := make([]int16, 0, len(allDigits)/numericBaseLen)
+	for n := 0; n < len(allDigits); n += numericBaseLen {
+		digit, err := strconv.Atoi(allDigits[n : n+numericBaseLen])
+		if err != nil {
+			return nil, 0, 0, 0, err
+		}
+		digits = append(digits, int16(digit))
+	}
+	weight := len(intPart)/numericBaseLen - 1
+
+	// Strips the leading and trailing zero digits.
+	for 0 < len(digits) && digits[0] == 0 {
+		digits = digits[1:]
+		weight--
+	}
+	for 0 < len(digits) && digits[len(digits)-1] == 0 {
+		digits = digits[:len(digits)-1]
+	}
+	if len(digits) == 0 {
+		return digits, 0, numericPositive, dscale, nil
+	}
+
+	return digits, weight, sign, dscale, nil
+}
+
+// shiftNumericPoint returns the integer and the fractional parts of the specified decimal digits
+// whose decimal point is moved by the specified exponent.
+func shiftNumericPoint(intPart string, fracPart string, exp int) (string, string) {
+	if exp == 0 {
+		return intPart, fracPart
+	}
+	digits := intPart + fracPart
+	point := len(intPart) + exp
+	switch {
+	case point <= 0:
+		return "", strings.Repeat("0", -point) + digits
+	case len(digits) <= point:
+		return digits + strings.Repeat("0", point-len(digits)), ""
+	}
+	return digits[:point], digits[point:]
+}
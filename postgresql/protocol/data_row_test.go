@@ -0,0 +1,99 @@
+// Copyright (C) 2019 The go-postgresql Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package protocol
+
+import (
+	"bytes"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/cybergarage/go-postgresql/postgresql/system"
+)
+
+func TestDataRowBinaryValues(t *testing.T) {
+	tests := []struct {
+		oid      ObjectID
+		v        any
+		expected []byte
+	}{
+		{system.Bool, true, []byte{0x01}},
+		{system.Int2, 1, []byte{0x00, 0x01}},
+		{system.Int4, "258", []byte{0x00, 0x00, 0x01, 0x02}},
+		{system.Int8, int64(-1), []byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}},
+		{system.Float8, 1.5, []byte{0x3F, 0xF8, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}},
+		{system.Text, "abc", []byte("abc")},
+		{system.JSONb, `{"a":1}`, []byte("\x01{\"a\":1}")},
+		{system.JSONb, []byte(`[]`), []byte("\x01[]")},
+		{system.Timestamp, time.Date(2000, 1, 1, 0, 0, 1, 0, time.UTC), []byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x0F, 0x42, 0x40}},
+		{system.Date, time.Date(2000, 1, 3, 0, 0, 0, 0, time.UTC), []byte{0x00, 0x00, 0x00, 0x02}},
+		// The values out of the time.Duration range, about 292 years from the PostgreSQL epoch.
+		{system.Date, time.Date(1999, 12, 31, 23, 59, 59, 0, time.UTC), []byte{0xFF, 0xFF, 0xFF, 0xFF}},
+		{system.Date, time.Date(1600, 1, 1, 0, 0, 0, 0, time.UTC), []byte{0xFF, 0xFD, 0xC5, 0x4F}},
+		{system.Timestamp, time.Date(9999, 12, 31, 23, 59, 59, 999999000, time.UTC), []byte{0x03, 0x80, 0xE7, 0x0B, 0x91, 0x3B, 0x7F, 0xFF}},
+		{system.Timestamptz, time.Date(1600, 1, 1, 0, 0, 0, 500000000, time.UTC), []byte{0xFF, 0xD3, 0x27, 0xA5, 0xD2, 0x5E, 0x41, 0x20}},
+		// ndigits=2, weight=0, sign=positive, dscale=2, digits=[12, 3400]
+		{system.Numeric, "12.34", []byte{0x00, 0x02, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0x00, 0x0C, 0x0D, 0x48}},
+		// ndigits=1, weight=1, sign=negative, dscale=0, digits=[1]
+		{system.Numeric, -10000, []byte{0x00, 0x01, 0x00, 0x01, 0x40, 0x00, 0x00, 0x00, 0x00, 0x01}},
+		// ndigits=0, weight=0, sign=infinity, dscale=0
+		{system.Numeric, math.Inf(1), []byte{0x00, 0x00, 0x00, 0x00, 0xD0, 0x00, 0x00, 0x00}},
+		{system.Numeric, "-Infinity", []byte{0x00, 0x00, 0x00, 0x00, 0xF0, 0x00, 0x00, 0x00}},
+		{system.Numeric, "NaN", []byte{0x00, 0x00, 0x00, 0x00, 0xC0, 0x00, 0x00, 0x00}},
+		// ndigits=1, weight=-1, sign=positive, dscale=4, digits=[15]
+		{system.Numeric, "1.5e-3", []byte{0x00, 0x01, 0xFF, 0xFF, 0x00, 0x00, 0x00, 0x04, 0x00, 0x0F}},
+		// ndigits=7, weight=5, sign=positive, dscale=2, digits=[1, 2345, 6789, 123, 4567, 8901, 2300]
+		{system.Numeric, "1.2345678901234567890123E20", []byte{0x00, 0x07, 0x00, 0x05, 0x00, 0x00, 0x00, 0x02, 0x00, 0x01, 0x09, 0x29, 0x1A, 0x85, 0x00, 0x7B, 0x11, 0xD7, 0x22, 0xC5, 0x08, 0xFC}},
+	}
+
+	for _, test := range tests {
+		field := NewRowFieldWith("c", WithRowFieldObjectID(test.oid), WithRowFieldFormatCode(BinaryFormat))
+		row := NewDataRow()
+		if err := row.AppendData(field, test.v); err != nil {
+			t.Error(err)
+			continue
+		}
+		actual, err := row.Bytes()
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		// Skips the message header, the number of columns and the value length.
+		header := 1 + MessageLengthSize + 2 + MessageLengthSize
+		if !bytes.Equal(actual[header:], test.expected) {
+			t.Errorf("%d (%v): Expected %v, but got %v", test.oid, test.v, test.expected, actual[header:])
+		}
+	}
+}
+
+func TestDataRowNullValue(t *testing.T) {
+	for _, formatCode := range []FormatCode{TextFormat, BinaryFormat} {
+		field := NewRowFieldWith("c", WithRowFieldObjectID(system.Int4), WithRowFieldFormatCode(formatCode))
+		row := NewDataRow()
+		if err := row.AppendData(field, nil); err != nil {
+			t.Error(err)
+			continue
+		}
+		actual, err := row.Bytes()
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		expected := []byte{'D', 0x00, 0x00, 0x00, 0x0A, 0x00, 0x01, 0xFF, 0xFF, 0xFF, 0xFF}
+		if !bytes.Equal(actual, expected) {
+			t.Errorf("Expected %v, but got %v", expected, actual)
+		}
+	}
+}
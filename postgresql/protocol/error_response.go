@@ -14,6 +14,13 @@
 
 package protocol
 
+import (
+	"strconv"
+
+	"github.com/cybergarage/go-postgresql/postgresql/errors"
+	sqlerrors "github.com/cybergarage/go-sqlparser/sql/errors"
+)
+
 // PostgreSQL: Documentation: 16: 55.2. Message Flow
 // https://www.postgresql.org/docs/16/protocol-flow.html
 // PostgreSQL: Documentation: 16: 55.7. Message Formats
@@ -25,23 +32,38 @@ package protocol
 type ErrorType byte
 
 const (
-	SeverityError         ErrorType = 'S'
-	CodeError             ErrorType = 'C'
-	MessageError          ErrorType = 'M'
-	DetailError           ErrorType = 'D'
-	HintError             ErrorType = 'H'
-	PositionError         ErrorType = 'P'
-	InternalPositionError ErrorType = 'p'
-	InternalQueryError    ErrorType = 'q'
-	WhereError            ErrorType = 'W'
-	SchemaError           ErrorType = 's'
-	TableError            ErrorType = 't'
-	ColumnError           ErrorType = 'c'
-	DataTypeNameError     ErrorType = 'd'
-	ConstraintError       ErrorType = 'n'
-	FileError             ErrorType = 'F'
-	LineError             ErrorType = 'L'
-	RoutineError          ErrorType = 'R'
+	SeverityError             ErrorType = 'S'
+	NonlocalizedSeverityError ErrorType = 'V'
+	CodeError                 ErrorType = 'C'
+	MessageError              ErrorType = 'M'
+	DetailError               ErrorType = 'D'
+	HintError                 ErrorType = 'H'
+	PositionError             ErrorType = 'P'
+	InternalPositionError     ErrorType = 'p'
+	InternalQueryError        ErrorType = 'q'
+	WhereError                ErrorType = 'W'
+	SchemaError               ErrorType = 's'
+	TableError                ErrorType = 't'
+	ColumnError               ErrorType = 'c'
+	DataTypeNameError         ErrorType = 'd'
+	ConstraintError           ErrorType = 'n'
+	FileError                 ErrorType = 'F'
+	LineError                 ErrorType = 'L'
+	RoutineError              ErrorType = 'R'
+)
+
+// Severity represents a severity of an error response.
+type Severity = errors.Severity
+
+const (
+	ErrorSeverity   = errors.ErrorSeverity
+	FatalSeverity   = errors.FatalSeverity
+	PanicSeverity   = errors.PanicSeverity
+	WarningSeverity = errors.WarningSeverity
+	NoticeSeverity  = errors.NoticeSeverity
+	DebugSeverity   = errors.DebugSeverity
+	InfoSeverity    = errors.InfoSeverity
+	LogSeverity     = errors.LogSeverity
 )
 
 // ErrorResponse represents an error response protocol.
@@ -51,15 +73,50 @@ type ErrorResponse struct {
 
 // NewErrorResponse returns a new error response instance.
 func NewErrorResponse() *ErrorResponse {
+	return newErrorResponseWithType(ErrorResponseMessage)
+}
+
+// newErrorResponseWithType returns a new response instance of the specified type which has the error fields.
+func newErrorResponseWithType(t Type) *ErrorResponse {
 	return &ErrorResponse{
-		ResponseMessage: NewResponseMessageWith(ErrorResponseMessage),
+		ResponseMessage: NewResponseMessageWith(t),
 	}
 }
 
 // NewErrorResponseWith returns a new error response instance with the specified error.
+// The fields of the SQL error in the error tree are sent if it has,
+// otherwise the SQLSTATE code is decided by the sentinel errors in the tree.
 func NewErrorResponseWith(err error) (*ErrorResponse, error) {
 	msg := NewErrorResponse()
-	return msg, msg.AddError(err)
+	return msg, msg.AddSQLError(newSQLErrorFrom(err))
+}
+
+// NewFatalErrorResponseWith returns a new fatal error response instance with the specified SQLSTATE code and error.
+func NewFatalErrorResponseWith(code sqlerrors.Code, err error) (*ErrorResponse, error) {
+	msg := NewErrorResponse()
+	sqlErr := errors.NewSQLErrorWith(code, err, errors.WithSQLErrorSeverity(FatalSeverity))
+	return msg, msg.AddSQLError(sqlErr)
+}
+
+// newSQLErrorFrom returns the SQL error in the specified error tree, or a new SQL error
+// with the SQLSTATE code registered for the sentinel errors in the tree.
+// The code is internal_error if no code is registered.
+func newSQLErrorFrom(err error) *errors.SQLError {
+	sqlErr, ok := errors.AsSQLError(err)
+	if !ok {
+		code, ok := errors.LookupSQLState(err)
+		if !ok {
+			code = sqlerrors.InternalError
+		}
+		return errors.NewSQLErrorWith(code, err)
+	}
+	if sqlErr == err {
+		return sqlErr
+	}
+	// Keeps the message of the wrapping errors.
+	wrappedErr := *sqlErr
+	wrappedErr.Message = err.Error()
+	return &wrappedErr
 }
 
 // AppendField appends an error field to the error response.
@@ -70,20 +127,76 @@ func (msg *ErrorResponse) AppendField(t ErrorType, v string) error {
 	return msg.AppendString(v)
 }
 
-// AddCode adds an error code to the error response.
-func (msg *ErrorResponse) AddCode(code int32) error {
-	if err := msg.AppendByte(byte(CodeError)); err != nil {
+// appendOptionalField appends an error field to the error response if the value is not empty.
+func (msg *ErrorResponse) appendOptionalField(t ErrorType, v string) error {
+	if len(v) == 0 {
+		return nil
+	}
+	return msg.AppendField(t, v)
+}
+
+// AddSeverity adds a localized and a non-localized severity to the error response.
+func (msg *ErrorResponse) AddSeverity(s Severity) error {
+	if err := msg.AppendField(SeverityError, string(s)); err != nil {
 		return err
 	}
-	return msg.AppendInt32(code)
+	return msg.AppendField(NonlocalizedSeverityError, string(s))
+}
+
+// AddCode adds a SQLSTATE error code to the error response.
+func (msg *ErrorResponse) AddCode(code sqlerrors.Code) error {
+	return msg.AppendField(CodeError, string(code))
 }
 
 // AddError adds an error message to the error response.
 func (msg *ErrorResponse) AddError(err error) error {
-	if err := msg.AppendByte(byte(MessageError)); err != nil {
+	return msg.AppendField(MessageError, err.Error())
+}
+
+// AddSQLError adds all fields of the specified SQL error to the error response.
+func (msg *ErrorResponse) AddSQLError(e *errors.SQLError) error {
+	severity := e.Severity
+	if len(severity) == 0 {
+		severity = ErrorSeverity
+	}
+	code := e.Code
+	if len(code) == 0 {
+		code = sqlerrors.InternalError
+	}
+	if err := msg.AddSeverity(severity); err != nil {
 		return err
 	}
-	return msg.AppendString(err.Error())
+	if err := msg.AddCode(code); err != nil {
+		return err
+	}
+	if err := msg.AppendField(MessageError, e.Message); err != nil {
+		return err
+	}
+	fields := []struct {
+		t ErrorType
+		v string
+	}{
+		{DetailError, e.Detail},
+		{HintError, e.Hint},
+		{PositionError, positionString(e.Position)},
+		{InternalPositionError, positionString(e.InternalPosition)},
+		{InternalQueryError, e.InternalQuery},
+		{WhereError, e.Where},
+		{SchemaError, e.Schema},
+		{TableError, e.Table},
+		{ColumnError, e.Column},
+		{DataTypeNameError, e.DataTypeName},
+		{ConstraintError, e.Constraint},
+		{FileError, e.File},
+		{LineError, positionString(e.Line)},
+		{RoutineError, e.Routine},
+	}
+	for _, field := range fields {
+		if err := msg.appendOptionalField(field.t, field.v); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // Bytes returns the message bytes after adding a null terminator.
@@ -93,3 +206,11 @@ func (msg *ErrorResponse) Bytes() ([]byte, error) {
 	}
 	return msg.ResponseMessage.Bytes()
 }
+
+// positionString returns the decimal string of the specified one-based position, or an empty string if it is not set.
+func positionString(pos int) string {
+	if pos <= 0 {
+		return ""
+	}
+	return strconv.Itoa(pos)
+}
@@ -17,6 +17,8 @@ package protocol
 import (
 	"errors"
 	"fmt"
+
+	"github.com/cybergarage/go-postgresql/postgresql/auth"
 )
 
 // ErrInvalid is returned when the message is invalid.
@@ -31,9 +33,6 @@ var ErrNotExist = errors.New("not exist")
 // ErrExist is returned when the specified object is exist.
 var ErrExist = errors.New("exist")
 
-// ErrTransactionBlocked is returned when the transaction is blocked.
-var ErrTransactionBlocked = errors.New("transaction blocked")
-
 func newShortMessageError(expected int, actual int) error {
 	return fmt.Errorf("%w short message : %d < %d", ErrInvalid, actual, expected)
 }
@@ -42,14 +41,26 @@ func newColumnTypeNotSuppotedError(v any) error {
 	return fmt.Errorf("column value type: %T is %w", v, ErrNotSupported)
 }
 
+func newErrBinaryFormatNotSupported(oid ObjectID, v any) error {
+	return fmt.Errorf("binary format of %T for data type (%d) is %w", v, oid, ErrNotSupported)
+}
+
 func newInvalidLengthError(v int) error {
 	return fmt.Errorf("%d is %w length", v, ErrInvalid)
 }
 
+func newErrInvalidNumericSign(v int) error {
+	return fmt.Errorf("numeric sign (0x%04X) is %w", v, ErrInvalid)
+}
+
 func newErrInvalidSSLRequestCode(v int32) error {
 	return fmt.Errorf("SSL request code (%d) is %w", v, ErrInvalid)
 }
 
+func newErrInvalidCancelRequestCode(v int32) error {
+	return fmt.Errorf("cancel request code (%d) is %w", v, ErrInvalid)
+}
+
 // NewErrMessageNotSuppoted returns a new message not supported error.
 func NewErrMessageNotSuppoted(t Type) error {
 	return fmt.Errorf("message type (%c:%02X) is %w", t, uint8(t), ErrNotSupported)
@@ -69,3 +80,15 @@ func NewErrNotExist(v any) error {
 func NewErrInvalidMessage(t Type) error {
 	return fmt.Errorf("message type (%c:%02X) is %w", t, uint8(t), ErrInvalid)
 }
+
+func newErrSASLMechanismNotSupported(mech string) error {
+	return fmt.Errorf("SASL mechanism (%s) is %w", mech, ErrNotSupported)
+}
+
+func newErrAuthMethodNotSupported(m auth.Method) error {
+	return fmt.Errorf("authentication method (%s) is %w", m, ErrNotSupported)
+}
+
+func newErrCredentialStoreNotFound(m auth.Method) error {
+	return fmt.Errorf("credential store for authentication method (%s) is %w", m, ErrNotExist)
+}
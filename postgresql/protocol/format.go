@@ -30,3 +30,20 @@ const (
 	// BinaryFormat represents a binary format code.
 	BinaryFormat FormatCode = system.BinaryFormat
 )
+
+// FormatCodes represents the format codes of parameters or result columns.
+type FormatCodes []FormatCode
+
+// FormatCode returns the format code of the specified zero-based column index.
+// No format codes denote the text format for all columns, and a single format code is applied to all columns.
+func (fmts FormatCodes) FormatCode(n int) FormatCode {
+	switch {
+	case len(fmts) == 0:
+		return TextFormat
+	case len(fmts) == 1:
+		return fmts[0]
+	case n < len(fmts):
+		return fmts[n]
+	}
+	return TextFormat
+}
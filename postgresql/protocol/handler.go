@@ -14,8 +14,14 @@
 
 package protocol
 
+import (
+	"github.com/cybergarage/go-postgresql/postgresql/auth"
+)
+
 // StartupHandler represents a start-up message handler.
 type StartupHandler interface {
+	// AuthenticationMethod returns the authentication method for the connection of the start-up message.
+	AuthenticationMethod(Conn, *Startup) (auth.Method, error)
 	// ParameterStatuses returns the parameter statuses.
 	ParameterStatuses(Conn) (Responses, error)
 	// BackendKeyData returns the backend key data.
@@ -0,0 +1,67 @@
+// Copyright (C) 2019 The go-postgresql Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package protocol
+
+// PostgreSQL: Documentation: 16: 55.2. Message Flow
+// https://www.postgresql.org/docs/16/protocol-flow.html
+
+// MessageState represents a message processing state of a connection.
+type MessageState int
+
+const (
+	// MessageIdleState denotes the connection is ready for a new query cycle.
+	MessageIdleState MessageState = iota
+	// ExtendedQueryState denotes the connection is processing extended query messages until the next Sync.
+	ExtendedQueryState
+	// ErrorPendingState denotes an error occurred in the extended query protocol and messages are discarded until the next Sync.
+	ErrorPendingState
+)
+
+// NextMessageState returns the next state after the specified message is processed.
+func (state MessageState) NextMessageState(t Type, hasError bool) MessageState {
+	if !t.IsExtendedQuery() {
+		return MessageIdleState
+	}
+	if hasError || state == ErrorPendingState {
+		return ErrorPendingState
+	}
+	return ExtendedQueryState
+}
+
+// IsReadyForQuery returns true if the connection should send a ReadyForQuery message in the state.
+func (state MessageState) IsReadyForQuery() bool {
+	return state == MessageIdleState
+}
+
+// IsDiscarded returns true if the specified message is discarded in the state.
+func (state MessageState) IsDiscarded(t Type) bool {
+	if state != ErrorPendingState {
+		return false
+	}
+	return t != SyncMessage && t != TerminateMessage
+}
+
+// String returns the string representation of the state.
+func (state MessageState) String() string {
+	switch state {
+	case MessageIdleState:
+		return "Idle"
+	case ExtendedQueryState:
+		return "ExtendedQuery"
+	case ErrorPendingState:
+		return "ErrorPending"
+	}
+	return ""
+}
@@ -0,0 +1,63 @@
+// Copyright (C) 2019 The go-postgresql Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package protocol
+
+import (
+	"testing"
+)
+
+func TestMessageState(t *testing.T) {
+	tests := []struct {
+		state    MessageState
+		msgType  Type
+		hasError bool
+		expected MessageState
+	}{
+		{MessageIdleState, QueryMessage, false, MessageIdleState},
+		{MessageIdleState, QueryMessage, true, MessageIdleState},
+		{MessageIdleState, ParseMessage, false, ExtendedQueryState},
+		{ExtendedQueryState, BindMessage, false, ExtendedQueryState},
+		{ExtendedQueryState, ExecuteMessage, true, ErrorPendingState},
+		{ErrorPendingState, ExecuteMessage, false, ErrorPendingState},
+		{ErrorPendingState, SyncMessage, false, MessageIdleState},
+		{ExtendedQueryState, SyncMessage, false, MessageIdleState},
+	}
+
+	for _, test := range tests {
+		next := test.state.NextMessageState(test.msgType, test.hasError)
+		if next != test.expected {
+			t.Errorf("%s (%s) : %s != %s", test.state, test.msgType, next, test.expected)
+		}
+	}
+
+	discardTests := []struct {
+		state    MessageState
+		msgType  Type
+		expected bool
+	}{
+		{MessageIdleState, BindMessage, false},
+		{ExtendedQueryState, BindMessage, false},
+		{ErrorPendingState, BindMessage, true},
+		{ErrorPendingState, QueryMessage, true},
+		{ErrorPendingState, SyncMessage, false},
+		{ErrorPendingState, TerminateMessage, false},
+	}
+
+	for _, test := range discardTests {
+		if test.state.IsDiscarded(test.msgType) != test.expected {
+			t.Errorf("%s (%s) : %t != %t", test.state, test.msgType, !test.expected, test.expected)
+		}
+	}
+}
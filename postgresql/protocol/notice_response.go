@@ -0,0 +1,46 @@
+// Copyright (C) 2019 The go-postgresql Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package protocol
+
+import (
+	"github.com/cybergarage/go-postgresql/postgresql/errors"
+)
+
+// PostgreSQL: Documentation: 16: 55.7. Message Formats
+// https://www.postgresql.org/docs/16/protocol-message-formats.html
+// PostgreSQL: Documentation: 16: 55.8. Error and Notice Message Fields
+// https://www.postgresql.org/docs/16/protocol-error-fields.html
+
+// NoticeResponse represents a notice response protocol.
+type NoticeResponse struct {
+	*ErrorResponse
+}
+
+// NewNoticeResponse returns a new notice response instance.
+func NewNoticeResponse() *NoticeResponse {
+	return &NoticeResponse{
+		ErrorResponse: newErrorResponseWithType(NoticeResponseMessage),
+	}
+}
+
+// NewNoticeResponseWith returns a new notice response instance with the specified notice.
+func NewNoticeResponseWith(notice *errors.SQLError) (*NoticeResponse, error) {
+	msg := NewNoticeResponse()
+	fields := *notice
+	if len(fields.Severity) == 0 {
+		fields.Severity = NoticeSeverity
+	}
+	return msg, msg.AddSQLError(&fields)
+}
@@ -29,6 +29,17 @@ type Parse struct {
 	DataTypes    []int32
 }
 
+// NewParseWith returns a new parse message with the specified statement name, query and parameter data types.
+func NewParseWith(name string, query string, dataTypes ...ObjectID) *Parse {
+	return &Parse{
+		RequestMessage: nil,
+		Name:           name,
+		Query:          query,
+		NumDataTypes:   int16(len(dataTypes)),
+		DataTypes:      dataTypes,
+	}
+}
+
 // NewParseWithReader returns a new parse message with the specified reader.
 func NewParseWithReader(reader *MessageReader) (*Parse, error) {
 	msg, err := NewRequestMessageWithReader(reader)
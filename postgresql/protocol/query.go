@@ -20,6 +20,7 @@ package protocol
 // https://www.postgresql.org/docs/16/protocol-message-formats.html
 
 import (
+	"encoding/hex"
 	"fmt"
 	"strings"
 
@@ -32,6 +33,7 @@ type Query struct {
 	Query string
 	BindParams
 	stmt.BindStatement
+	ResultFormats FormatCodes
 }
 
 // NewQueryWithReader returns a new query message with specified reader.
@@ -44,29 +46,41 @@ func NewQueryWithReader(reader *MessageReader) (*Query, error) {
 	if err != nil {
 		return nil, err
 	}
+	q := NewQueryWithString(query)
+	q.RequestMessage = msg
+	return q, nil
+}
+
+// NewQueryWithString returns a new simple query message with the specified query string.
+func NewQueryWithString(query string) *Query {
 	q := &Query{
-		RequestMessage: msg,
+		RequestMessage: nil,
 		Query:          query,
 		BindParams:     BindParams{},
 		BindStatement:  nil,
+		ResultFormats:  FormatCodes{},
 	}
 	q.BindStatement = stmt.NewBindStatement(
 		stmt.WithBindStatementQuery(q.Query),
 	)
-	return q, nil
+	return q
 }
 
 // NewQueryWith returns a new query message with specified parameters.
 func NewQueryWith(parseMsg *Parse, bindMsg *Bind) (*Query, error) {
+	if err := bindMsg.Params.Decode(parseMsg.DataTypes); err != nil {
+		return nil, err
+	}
 	q := &Query{
 		RequestMessage: nil,
 		Query:          parseMsg.Query,
 		BindParams:     bindMsg.Params,
 		BindStatement:  nil,
+		ResultFormats:  bindMsg.ResultFormats,
 	}
 	bindParams := stmt.BindParams{}
 	for _, param := range bindMsg.Params {
-		bindParams = append(bindParams, stmt.NewBindParam(param.Value))
+		bindParams = append(bindParams, newQueryBindParam(param.Value))
 	}
 	q.BindStatement = stmt.NewBindStatement(
 		stmt.WithBindStatementQuery(q.Query),
@@ -90,8 +104,38 @@ func (q *Query) String() string {
 			if 0 < i {
 				s.WriteString(", ")
 			}
-			s.WriteString(fmt.Sprintf("%s", param.Value))
+			s.WriteString(fmt.Sprintf("%v", param.Value))
 		}
 	}
 	return s.String()
 }
+
+// queryBindParam represents a bind parameter which is embedded into the query string.
+type queryBindParam struct {
+	stmt.BindParam
+	v any
+}
+
+// newQueryBindParam returns a new bind parameter of the specified value.
+func newQueryBindParam(v any) stmt.BindParam {
+	return &queryBindParam{
+		BindParam: stmt.NewBindParam(v),
+		v:         v,
+	}
+}
+
+// String returns the SQL literal of the bind parameter.
+func (param *queryBindParam) String() (string, error) {
+	switch v := param.v.(type) {
+	case nil:
+		return "NULL", nil
+	case string:
+		// The single quotes are doubled, and the backslashes are kept as they are
+		// because they are not escape characters in the standard conforming strings.
+		return "'" + strings.ReplaceAll(v, "'", "''") + "'", nil
+	case []byte:
+		// The hex format of bytea values.
+		return `'\x` + hex.EncodeToString(v) + "'", nil
+	}
+	return param.BindParam.String()
+}
@@ -18,7 +18,7 @@ package protocol
 // https://www.postgresql.org/docs/16/protocol-message-formats.html
 
 import (
-	util "github.com/cybergarage/go-postgresql/postgresql/encoding/bytes"
+	"encoding/binary"
 )
 
 // ResponseMessage represents a backend response instance.
@@ -34,10 +34,14 @@ func NewResponseMessage() *ResponseMessage {
 
 // NewResponseMessageWith returns a new response message with the specified message type.
 func NewResponseMessageWith(t Type) *ResponseMessage {
-	return &ResponseMessage{
+	msg := &ResponseMessage{
 		typ:    t,
 		Writer: NewWriter(),
 	}
+	// Reserves the message header to fill the type and the length without copying the message content.
+	var header [MessageHeaderSize]byte
+	_ = msg.AppendBytes(header[:])
+	return msg
 }
 
 // SetType sets a message type.
@@ -50,17 +54,17 @@ func (msg *ResponseMessage) Type() Type {
 	return msg.typ
 }
 
-// Bytes appends a length of the message content bytes, and returns the message bytes.
+// Bytes fills a length of the message content bytes in the reserved header, and returns the message bytes.
+// The returned bytes are valid until the message is released.
 func (msg *ResponseMessage) Bytes() ([]byte, error) {
 	msgBytes, err := msg.Writer.Bytes()
 	if err != nil {
 		return nil, err
 	}
-	l := len(msgBytes)
-	b := make([]byte, 0, 1+4+l)
-	if msg.typ != NoneMessage {
-		b = append(b, byte(msg.typ))
+	msgBytes[0] = byte(msg.typ)
+	binary.BigEndian.PutUint32(msgBytes[MessageTypeSize:], uint32(len(msgBytes)-MessageTypeSize))
+	if msg.typ == NoneMessage {
+		return msgBytes[MessageTypeSize:], nil
 	}
-	b = append(b, util.Int32ToBytes(int32(l+4))...)
-	return append(b, msgBytes...), nil
+	return msgBytes, nil
 }
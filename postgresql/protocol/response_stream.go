@@ -0,0 +1,63 @@
+// Copyright (C) 2019 The go-postgresql Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package protocol
+
+// ResponseStream represents a stream of response messages which are produced one by one while they are sent,
+// so that all response messages of a large result are not held in memory.
+type ResponseStream interface {
+	// Next prepares the next response message. It returns false when no more response messages remain.
+	Next() bool
+	// Response returns the current response message.
+	Response() (Response, error)
+	// Close releases the resources of the stream.
+	Close() error
+}
+
+// responsesStream represents a response stream of the materialized response messages.
+type responsesStream struct {
+	responses Responses
+	current   Response
+}
+
+// NewResponseStreamWith returns a new response stream of the specified response messages.
+func NewResponseStreamWith(responses Responses) ResponseStream {
+	return &responsesStream{
+		responses: responses,
+		current:   nil,
+	}
+}
+
+// Next prepares the next response message. It returns false when no more response messages remain.
+func (stream *responsesStream) Next() bool {
+	if len(stream.responses) == 0 {
+		stream.current = nil
+		return false
+	}
+	stream.current = stream.responses[0]
+	stream.responses = stream.responses[1:]
+	return true
+}
+
+// Response returns the current response message.
+func (stream *responsesStream) Response() (Response, error) {
+	return stream.current, nil
+}
+
+// Close releases the resources of the stream.
+func (stream *responsesStream) Close() error {
+	stream.responses = nil
+	stream.current = nil
+	return nil
+}
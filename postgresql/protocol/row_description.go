@@ -46,6 +46,19 @@ func (msg *RowDescription) Field(n int) *RowField {
 	return msg.fileds[n]
 }
 
+// Fields returns all fields.
+func (msg *RowDescription) Fields() []*RowField {
+	return msg.fileds
+}
+
+// SetResultFormats sets the result-column format codes which are requested by a Bind message to the fields.
+// The DataRow messages of the fields are encoded in the format codes.
+func (msg *RowDescription) SetResultFormats(fmts FormatCodes) {
+	for n, field := range msg.fileds {
+		field.FormatCode = fmts.FormatCode(n)
+	}
+}
+
 // Bytes appends a length of the message content bytes, and returns the message bytes.
 func (msg *RowDescription) Bytes() ([]byte, error) {
 	msg.AppendInt16(int16(len(msg.fileds)))
@@ -95,11 +95,11 @@ func WithRowFieldFormatCode(formatCode int16) func(*RowField) {
 }
 
 // WithRowFieldDataType sets a data type.
+// The format code is not derived from the data type, it is the text format unless the result formats are requested by a Bind message.
 func WithRowFieldDataType(dt *DataType) func(*RowField) {
 	return func(fileld *RowField) {
 		fileld.ObjectID = dt.OID()
 		fileld.DataTypeSize = int16(dt.Size())
-		fileld.FormatCode = dt.FormatCode()
 	}
 }
 
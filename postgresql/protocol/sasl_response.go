@@ -0,0 +1,86 @@
+// Copyright (C) 2019 The go-postgresql Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package protocol
+
+// PostgreSQL: Documentation: 16: 55.3. SASL Authentication
+// https://www.postgresql.org/docs/16/sasl-authentication.html
+// PostgreSQL: Documentation: 16: 55.7. Message Formats
+// https://www.postgresql.org/docs/16/protocol-message-formats.html
+
+// SASLInitialResponse represents a SASLInitialResponse protocol.
+type SASLInitialResponse struct {
+	*RequestMessage
+
+	Mechanism string
+	Data      []byte
+}
+
+// NewSASLInitialResponseWithReader returns a new SASLInitialResponse message with the specified reader.
+func NewSASLInitialResponseWithReader(reader *MessageReader) (*SASLInitialResponse, error) {
+	msg, err := NewRequestMessageWithReader(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	mech, err := reader.ReadString()
+	if err != nil {
+		return nil, err
+	}
+
+	dataLen, err := reader.ReadInt32()
+	if err != nil {
+		return nil, err
+	}
+
+	var data []byte
+	if 0 < dataLen {
+		data = make([]byte, dataLen)
+		_, err = reader.ReadBytes(data)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &SASLInitialResponse{
+		RequestMessage: msg,
+		Mechanism:      mech,
+		Data:           data,
+	}, nil
+}
+
+// SASLResponse represents a SASLResponse protocol.
+type SASLResponse struct {
+	*RequestMessage
+
+	Data []byte
+}
+
+// NewSASLResponseWithReader returns a new SASLResponse message with the specified reader.
+func NewSASLResponseWithReader(reader *MessageReader) (*SASLResponse, error) {
+	msg, err := NewRequestMessageWithReader(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := msg.ReadMessageData()
+	if err != nil {
+		return nil, err
+	}
+
+	return &SASLResponse{
+		RequestMessage: msg,
+		Data:           data,
+	}, nil
+}
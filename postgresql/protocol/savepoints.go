@@ -0,0 +1,80 @@
+// Copyright (C) 2019 The go-postgresql Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package protocol
+
+// PostgreSQL: Documentation: 16: SAVEPOINT
+// https://www.postgresql.org/docs/16/sql-savepoint.html
+
+// Savepoints represents the savepoint stack of a transaction block.
+// A savepoint can be defined with the same name of an older savepoint, and the newer one is used until it is released.
+type Savepoints struct {
+	names []string
+}
+
+// NewSavepoints returns a new empty savepoint stack.
+func NewSavepoints() *Savepoints {
+	return &Savepoints{
+		names: []string{},
+	}
+}
+
+// Push defines a new savepoint with the specified name.
+func (savepoints *Savepoints) Push(name string) {
+	savepoints.names = append(savepoints.names, name)
+}
+
+// Contains returns true if the savepoint with the specified name is defined.
+func (savepoints *Savepoints) Contains(name string) bool {
+	return 0 <= savepoints.lastIndex(name)
+}
+
+// Release destroys the newest savepoint with the specified name and all savepoints defined after it.
+func (savepoints *Savepoints) Release(name string) bool {
+	idx := savepoints.lastIndex(name)
+	if idx < 0 {
+		return false
+	}
+	savepoints.names = savepoints.names[:idx]
+	return true
+}
+
+// RollbackTo destroys all savepoints defined after the newest savepoint with the specified name, and keeps the savepoint.
+func (savepoints *Savepoints) RollbackTo(name string) bool {
+	idx := savepoints.lastIndex(name)
+	if idx < 0 {
+		return false
+	}
+	savepoints.names = savepoints.names[:idx+1]
+	return true
+}
+
+// Names returns the savepoint names in the defined order.
+func (savepoints *Savepoints) Names() []string {
+	return append([]string{}, savepoints.names...)
+}
+
+// Clear destroys all savepoints.
+func (savepoints *Savepoints) Clear() {
+	savepoints.names = savepoints.names[:0]
+}
+
+func (savepoints *Savepoints) lastIndex(name string) int {
+	for n := len(savepoints.names) - 1; 0 <= n; n-- {
+		if savepoints.names[n] == name {
+			return n
+		}
+	}
+	return -1
+}
@@ -0,0 +1,57 @@
+// Copyright (C) 2019 The go-postgresql Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package protocol
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestSavepoints(t *testing.T) {
+	savepoints := NewSavepoints()
+	for _, name := range []string{"a", "b", "a", "c"} {
+		savepoints.Push(name)
+	}
+
+	tests := []struct {
+		op       string
+		name     string
+		ok       bool
+		expected []string
+	}{
+		{"rollback", "a", true, []string{"a", "b", "a"}},
+		{"release", "a", true, []string{"a", "b"}},
+		{"release", "c", false, []string{"a", "b"}},
+		{"rollback", "a", true, []string{"a"}},
+		{"release", "a", true, []string{}},
+		{"rollback", "a", false, []string{}},
+	}
+
+	for _, test := range tests {
+		var ok bool
+		switch test.op {
+		case "rollback":
+			ok = savepoints.RollbackTo(test.name)
+		case "release":
+			ok = savepoints.Release(test.name)
+		}
+		if ok != test.ok {
+			t.Errorf("%s %s : %t != %t", test.op, test.name, ok, test.ok)
+		}
+		if fmt.Sprintf("%v", savepoints.Names()) != fmt.Sprintf("%v", test.expected) {
+			t.Errorf("%s %s : %v != %v", test.op, test.name, savepoints.Names(), test.expected)
+		}
+	}
+}
@@ -0,0 +1,312 @@
+// Copyright (C) 2019 The go-postgresql Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package protocol
+
+import (
+	"errors"
+	"net"
+	"slices"
+
+	"github.com/cybergarage/go-postgresql/postgresql/auth"
+	sqlerrors "github.com/cybergarage/go-sqlparser/sql/errors"
+)
+
+// PostgreSQL: Documentation: 16: 55.2. Message Flow
+// https://www.postgresql.org/docs/16/protocol-flow.html
+// PostgreSQL: Documentation: 16: 55.3. SASL Authentication
+// https://www.postgresql.org/docs/16/sasl-authentication.html
+
+// authenticate authenticates the client of the specified startup message with the authentication method
+// which the startup handler decides for the connection.
+// If host-based authentication rules are configured, the method of the first matched rule is used instead,
+// and the connection is rejected before any credential exchange if no rule matches.
+// The MD5 and SCRAM-SHA-256 methods require a credential store which has the passwords,
+// so the connection is rejected if the methods are selected without a credential store.
+func (server *server) authenticate(conn Conn, startupMsg *Startup) error {
+	var err error
+	user, ok := startupMsg.User()
+	if !ok {
+		return auth.NewErrAuthrizationFailed(user)
+	}
+
+	var method auth.Method
+	var rule *auth.HBARule
+	if hba := server.HBA(); hba != nil {
+		rule, err = lookupHBARule(hba, conn, startupMsg)
+		if rule != nil {
+			method = rule.Method()
+		}
+	} else {
+		method, err = server.MessageHandler.AuthenticationMethod(conn, startupMsg)
+	}
+	if err != nil {
+		return err
+	}
+	switch method { // nolint:exhaustive
+	case auth.MethodMD5, auth.MethodSCRAMSHA256:
+		if server.CredentialStore() == nil {
+			return newErrCredentialStoreNotFound(method)
+		}
+	}
+
+	switch method {
+	case auth.MethodTrust:
+		ok = true
+	case auth.MethodReject:
+		ok = false
+	case auth.MethodPassword:
+		ok, err = server.authenticateCleartextPassword(conn, user)
+	case auth.MethodMD5:
+		ok, err = server.authenticateMD5Password(conn, user)
+	case auth.MethodSCRAMSHA256:
+		ok, err = server.authenticateSCRAM(conn, user)
+	case auth.MethodCert:
+		ok, err = server.authenticateCertificate(conn, user, rule)
+	default:
+		err = newErrAuthMethodNotSupported(method)
+	}
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return auth.NewErrAuthrizationFailed(user)
+	}
+
+	res, err := NewAuthenticationOk()
+	if err != nil {
+		return err
+	}
+	err = conn.ResponseMessage(res)
+	if err != nil {
+		return err
+	}
+
+	conn.SetUser(user)
+
+	return nil
+}
+
+// lookupHBARule returns the first host-based authentication rule
+// which matches the connection of the specified startup message.
+func lookupHBARule(hba *auth.HBA, conn Conn, startupMsg *Startup) (*auth.HBARule, error) {
+	user, _ := startupMsg.User()
+	db, ok := startupMsg.Database()
+	if !ok {
+		// The database name defaults to the user name.
+		db = user
+	}
+	q := &auth.HBAQuery{
+		Local:    false,
+		TLS:      conn.IsTLSConnection(),
+		Addr:     nil,
+		Database: db,
+		User:     user,
+	}
+	switch addr := conn.RemoteAddr().(type) {
+	case *net.UnixAddr:
+		q.Local = true
+	case *net.TCPAddr:
+		q.Addr = addr.IP
+	}
+	rule, ok := hba.Lookup(q)
+	if !ok {
+		return nil, auth.NewErrNoHBAEntry(q)
+	}
+	if rule.Method() == auth.MethodReject {
+		return nil, auth.NewErrHBAReject(q)
+	}
+	return rule, nil
+}
+
+// newStartupErrorResponseWith returns a fatal error response for the specified start-up error.
+func newStartupErrorResponseWith(err error) (*ErrorResponse, error) {
+	code := sqlerrors.ConnectionException
+	switch {
+	case errors.Is(err, auth.ErrAuthrizationFailed):
+		code = sqlerrors.InvalidPassword
+	case errors.Is(err, auth.ErrNoHBAEntry), errors.Is(err, auth.ErrHBAReject):
+		code = sqlerrors.InvalidAuthorizationSpecification
+	}
+	return NewFatalErrorResponseWith(code, err)
+}
+
+// authenticateCleartextPassword authenticates the client with a cleartext password.
+func (server *server) authenticateCleartextPassword(conn Conn, user string) (bool, error) {
+	authMsg, err := NewAuthenticationCleartextPassword()
+	if err != nil {
+		return false, err
+	}
+	err = conn.ResponseMessage(authMsg)
+	if err != nil {
+		return false, err
+	}
+	msg, err := NewPasswordWithReader(conn.MessageReader())
+	if err != nil {
+		return false, err
+	}
+	// Hashed passwords in the credential store are verified here
+	// because the credential authenticator compares the passwords as they are.
+	storedPassword, ok, err := auth.LookupPassword(server.CredentialStore(), user, auth.MethodPassword.String())
+	if err != nil {
+		return false, err
+	}
+	if ok && auth.IsHashedPassword(storedPassword) {
+		return auth.VerifyPassword(user, storedPassword, msg.Password), nil
+	}
+	q, err := auth.NewQuery(
+		auth.WithQueryUsername(user),
+		auth.WithQueryPassword(msg.Password),
+	)
+	if err != nil {
+		return false, err
+	}
+	return server.VerifyCredential(conn, q)
+}
+
+// authenticateMD5Password authenticates the client with a MD5 hashed password and a random salt.
+// As PostgreSQL does, SCRAM-SHA-256 is used instead if the stored password is a SCRAM verifier.
+func (server *server) authenticateMD5Password(conn Conn, user string) (bool, error) {
+	storedPassword, hasPassword, err := auth.LookupPassword(server.CredentialStore(), user, auth.MD5)
+	if err != nil {
+		return false, err
+	}
+	if hasPassword && auth.IsSCRAMVerifier(storedPassword) {
+		return server.authenticateSCRAM(conn, user)
+	}
+
+	salt, err := auth.NewMD5Salt()
+	if err != nil {
+		return false, err
+	}
+	authMsg, err := NewAuthenticationMD5Password(salt)
+	if err != nil {
+		return false, err
+	}
+	err = conn.ResponseMessage(authMsg)
+	if err != nil {
+		return false, err
+	}
+	msg, err := NewPasswordWithReader(conn.MessageReader())
+	if err != nil {
+		return false, err
+	}
+	if !hasPassword {
+		return false, nil
+	}
+	return auth.VerifyMD5Password(user, storedPassword, salt, msg.Password), nil
+}
+
+// authenticateSCRAM authenticates the client with the SCRAM-SHA-256 SASL exchange.
+// SCRAM-SHA-256-PLUS is also advertised for TLS connections to bind the exchange to the server certificate.
+func (server *server) authenticateSCRAM(conn Conn, user string) (bool, error) {
+	var cbData []byte
+	mechs := []string{auth.SCRAMSHA256}
+	if cert, ok := conn.TLSServerCertificate(); conn.IsTLSConnection() && ok {
+		data, err := auth.NewTLSServerEndPointWith(cert)
+		if err == nil {
+			cbData = data
+			mechs = []string{auth.SCRAMSHA256Plus, auth.SCRAMSHA256}
+		}
+	}
+
+	authMsg, err := NewAuthenticationSASL(mechs...)
+	if err != nil {
+		return false, err
+	}
+	err = conn.ResponseMessage(authMsg)
+	if err != nil {
+		return false, err
+	}
+
+	initMsg, err := NewSASLInitialResponseWithReader(conn.MessageReader())
+	if err != nil {
+		return false, err
+	}
+	if !slices.Contains(mechs, initMsg.Mechanism) {
+		return false, newErrSASLMechanismNotSupported(initMsg.Mechanism)
+	}
+
+	scramServer := auth.NewSCRAMServer(
+		auth.WithSCRAMServerCredentialStore(server.CredentialStore()),
+		auth.WithSCRAMServerUsername(user),
+		auth.WithSCRAMServerMechanism(initMsg.Mechanism),
+		auth.WithSCRAMServerChannelBinding(cbData),
+		auth.WithSCRAMServerMockNonce(server.scramMockNonce),
+	)
+
+	serverFirstMsg, err := scramServer.FirstMessageFrom(initMsg.Data)
+	if err != nil {
+		return false, err
+	}
+	authMsg, err = NewAuthenticationSASLContinue(serverFirstMsg)
+	if err != nil {
+		return false, err
+	}
+	err = conn.ResponseMessage(authMsg)
+	if err != nil {
+		return false, err
+	}
+
+	resMsg, err := NewSASLResponseWithReader(conn.MessageReader())
+	if err != nil {
+		return false, err
+	}
+	serverFinalMsg, err := scramServer.FinalMessageFrom(resMsg.Data)
+	if err != nil {
+		return false, err
+	}
+	authMsg, err = NewAuthenticationSASLFinal(serverFinalMsg)
+	if err != nil {
+		return false, err
+	}
+	err = conn.ResponseMessage(authMsg)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// authenticateCertificate authenticates the client with the TLS client certificate only.
+// If the matched host-based authentication rule has a map option, the common name or a subject alternative name
+// of the client certificate must be mapped to the user name by the user name map.
+// Otherwise, the default user name map is used if the user name maps have it,
+// and the common name of the client certificate must be the same as the user name if not.
+func (server *server) authenticateCertificate(conn Conn, user string, rule *auth.HBARule) (bool, error) {
+	if !conn.IsTLSConnection() {
+		return false, nil
+	}
+	certs := conn.TLSConn().ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		return false, nil
+	}
+
+	identMap := server.IdentMap()
+	mapName := ""
+	if rule != nil {
+		mapName, _ = rule.Option(auth.HBAMapOption)
+	}
+	if len(mapName) == 0 {
+		if identMap == nil || !identMap.HasMap(auth.DefaultIdentMapName) {
+			return certs[0].Subject.CommonName == user, nil
+		}
+		mapName = auth.DefaultIdentMapName
+	}
+
+	if identMap == nil {
+		return false, nil
+	}
+	return identMap.Matches(mapName, auth.NewCertificateIdentities(certs[0]), user), nil
+}
@@ -15,9 +15,14 @@
 package protocol
 
 import (
+	"crypto/rand"
+	"crypto/subtle"
 	"crypto/tls"
+	"math"
+	"math/big"
 	"net"
 	"strconv"
+	"sync/atomic"
 
 	"github.com/cybergarage/go-logger/log"
 	"github.com/cybergarage/go-postgresql/postgresql/auth"
@@ -34,10 +39,18 @@ type server struct {
 	tcpListener net.Listener
 	MessageHandler
 	auth.Manager
+	lastProcessID  atomic.Int32
+	scramMockNonce []byte
 }
 
 // NewServer returns a new server instance.
 func NewServer() Server {
+	// The nonce to derive the salts of mock SCRAM verifiers is kept while the server is alive
+	// as the mock authentication nonce of PostgreSQL.
+	scramMockNonce, err := auth.NewSCRAMMockNonce()
+	if err != nil {
+		log.Error(err)
+	}
 	server := &server{
 		Config:         NewDefaultConfig(),
 		ConnManager:    pgnet.NewConnManager(),
@@ -45,6 +58,8 @@ func NewServer() Server {
 		tcpListener:    nil,
 		MessageHandler: nil,
 		Manager:        auth.NewManager(),
+		lastProcessID:  atomic.Int32{},
+		scramMockNonce: scramMockNonce,
 	}
 	return server
 }
@@ -143,53 +158,11 @@ func (server *server) receive(netConn net.Conn) error { //nolint:gocyclo,maintid
 
 	log.Debugf("%s/%s (%s) accepted", server.ProductName(), server.ProductVersion(), netConn.RemoteAddr().String())
 
-	authenticateStartup := func(conn Conn, startupMsg *Startup) (Response, error) {
-		authenticate := func(conn Conn, startupMsg *Startup) (bool, error) {
-			clientUsername, ok := startupMsg.User()
-			if !ok {
-				return false, nil
-			}
-			authMsg, err := NewAuthenticationCleartextPassword()
-			if err != nil {
-				return false, err
-			}
-			err = conn.ResponseMessage(authMsg)
-			if err != nil {
-				return false, err
-			}
-			msg, err := NewPasswordWithReader(conn.MessageReader())
-			if err != nil {
-				return false, err
-			}
-			q, err := auth.NewQuery(
-				auth.WithQueryUsername(clientUsername),
-				auth.WithQueryPassword(msg.Password),
-			)
-			if err != nil {
-				return false, err
-			}
-			return server.VerifyCredential(conn, q)
-		}
-
-		ok, err := authenticate(conn, startupMsg)
-		if err != nil {
-			return nil, err
-		}
-		if ok {
-			return NewAuthenticationOk()
-		}
-		return NewErrorResponse(), nil
-	}
-
 	handleStartupMessage := func(conn Conn, startupMsg *Startup) error {
 		// PostgreSQL: Documentation: 16: 55.2. Message Flow
 		// https://www.postgresql.org/docs/16/protocol-flow.html
 		// Handle the Start-up message and return an Authentication message or error
-		res, err := authenticateStartup(conn, startupMsg)
-		if err != nil {
-			return err
-		}
-		err = conn.ResponseMessage(res)
+		err := server.authenticate(conn, startupMsg)
 		if err != nil {
 			return err
 		}
@@ -203,7 +176,7 @@ func (server *server) receive(netConn net.Conn) error { //nolint:gocyclo,maintid
 			return err
 		}
 		// Return BackendKeyData (K)
-		res, err = server.MessageHandler.BackendKeyData(conn)
+		res, err := server.MessageHandler.BackendKeyData(conn)
 		if err != nil {
 			return err
 		}
@@ -219,9 +192,16 @@ func (server *server) receive(netConn net.Conn) error { //nolint:gocyclo,maintid
 		return nil
 	}
 
+	pid, key, err := server.newBackendKeyData()
+	if err != nil {
+		return err
+	}
+
 	conn := NewConnWith(
 		netConn,
 		WithConnSchemas(system.DefaultSchema),
+		WithConnBackendKeyData(pid, key),
+		WithConnWriteBufferSize(server.Config.WriteBufferSize()),
 	)
 	defer func() {
 		conn.Close()
@@ -253,6 +233,10 @@ func (server *server) receive(netConn net.Conn) error { //nolint:gocyclo,maintid
 			if err != nil {
 				return err
 			}
+			var serverCert *tls.Certificate
+			tlsConfig = newTLSConfigWithCertificateRecorder(tlsConfig, func(cert *tls.Certificate) {
+				serverCert = cert
+			})
 			tlsConn := tls.Server(conn, tlsConfig)
 			if err := tlsConn.Handshake(); err != nil {
 				conn.ResponseError(err)
@@ -263,11 +247,18 @@ func (server *server) receive(netConn net.Conn) error { //nolint:gocyclo,maintid
 				conn.ResponseError(err)
 				return err
 			}
-			conn = NewConnWith(
-				tlsConn,
+			connOpts := []connOption{
 				WithConnTLSConn(tlsConn),
 				WithConnSchemas(system.DefaultSchema),
-			)
+				WithConnBackendKeyData(pid, key),
+				WithConnWriteBufferSize(server.Config.WriteBufferSize()),
+			}
+			if serverCert != nil {
+				if cert, err := newX509CertificateFrom(serverCert); err == nil {
+					connOpts = append(connOpts, WithConnTLSServerCertificate(cert))
+				}
+			}
+			conn = NewConnWith(tlsConn, connOpts...)
 		} else {
 			err = conn.ResponseMessage(NewSSLResponseWith(SSLDisabled))
 			if err != nil {
@@ -280,6 +271,16 @@ func (server *server) receive(netConn net.Conn) error { //nolint:gocyclo,maintid
 
 	reader := conn.MessageReader()
 
+	// Handle a CancelRequest which is sent with a new connection instead of a Start-up
+
+	isCancelReq, err := IsCancelRequest(reader)
+	if err != nil {
+		return err
+	}
+	if isCancelReq {
+		return server.cancel(reader)
+	}
+
 	// Handle a Start-up
 
 	startupMsg, err := NewStartupWithReader(reader)
@@ -290,7 +291,10 @@ func (server *server) receive(netConn net.Conn) error { //nolint:gocyclo,maintid
 
 	err = handleStartupMessage(conn, startupMsg)
 	if err != nil {
-		conn.ResponseError(err)
+		// Errors in the start-up phase are reported as FATAL and the connection is closed.
+		if errMsg, resErr := newStartupErrorResponseWith(err); resErr == nil {
+			conn.ResponseMessage(errMsg)
+		}
 		return err
 	}
 
@@ -318,6 +322,22 @@ func (server *server) receive(netConn net.Conn) error { //nolint:gocyclo,maintid
 			break
 		}
 
+		conn.resetContext()
+
+		// PostgreSQL: Documentation: 16: 55.2.3. Extended Query
+		// https://www.postgresql.org/docs/16/protocol-flow.html#PROTOCOL-FLOW-EXT-QUERY
+		// When an error is detected while processing any extended-query message, the backend issues ErrorResponse,
+		// then reads and discards messages until a Sync is reached, then issues ReadyForQuery and returns to normal message processing.
+
+		if conn.MessageState().IsDiscarded(reqType) {
+			reqErr = conn.SkipMessage()
+			if reqErr != nil {
+				conn.ResponseError(reqErr)
+				break
+			}
+			continue
+		}
+
 		loopSpan := server.Tracer.StartSpan(server.ProductName())
 		conn.SetSpanContext(loopSpan)
 		conn.StartSpan(reqType.String())
@@ -406,10 +426,18 @@ func (server *server) receive(netConn net.Conn) error { //nolint:gocyclo,maintid
 			}
 		}
 
-		// Return ReadyForQuery (B)
+		// An error in a transaction block aborts the transaction until the end of the transaction block.
 
-		_, err = reader.PeekTypeNonBlocking()
-		if err == nil {
+		hasError := reqErr != nil || resMsgs.HasErrorResponse()
+		if hasError {
+			conn.SetTransactionState(conn.TransactionState().NextTransactionState(TransactionNoneCommand, hasError))
+		}
+
+		// Return ReadyForQuery (B) only at the end of a simple Query or an extended query cycle terminated by a Sync
+
+		conn.setMessageState(conn.MessageState().NextMessageState(reqType, hasError))
+		if !conn.MessageState().IsReadyForQuery() {
+			loopSpan.Span().Finish()
 			continue
 		}
 
@@ -426,3 +454,37 @@ func (server *server) receive(netConn net.Conn) error { //nolint:gocyclo,maintid
 
 	return nil
 }
+
+// newBackendKeyData returns a new unique process ID and a new random secret key for a connection.
+func (server *server) newBackendKeyData() (int32, int32, error) {
+	pid := server.lastProcessID.Add(1)
+	key, err := rand.Int(rand.Reader, big.NewInt(math.MaxInt32))
+	if err != nil {
+		return 0, 0, err
+	}
+	return pid, int32(key.Int64()), nil
+}
+
+// cancel handles a CancelRequest and cancels the running query of the connection which has the backend key data.
+// PostgreSQL: Documentation: 16: 55.2.9. Canceling Requests in Progress
+// https://www.postgresql.org/docs/16/protocol-flow.html#PROTOCOL-FLOW-CANCELING-REQUESTS
+func (server *server) cancel(reader *MessageReader) error {
+	cancelMsg, err := NewCancelRequestWithReader(reader)
+	if err != nil {
+		return err
+	}
+	// The server does not reply to the CancelRequest for security reasons.
+	netConn, ok := server.LookupConnByUID(ConnID(cancelMsg.ProcessID))
+	if !ok {
+		return nil
+	}
+	conn, ok := netConn.(CancelConn)
+	if !ok {
+		return nil
+	}
+	if subtle.ConstantTimeEq(conn.SecretKey(), cancelMsg.SecretKey) != 1 {
+		return nil
+	}
+	conn.Cancel()
+	return nil
+}
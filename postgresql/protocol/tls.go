@@ -0,0 +1,71 @@
+// Copyright (C) 2019 The go-postgresql Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package protocol
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+)
+
+// newTLSConfigWithCertificateRecorder returns a copy of the specified TLS configuration
+// which records the server certificate selected in the handshake.
+func newTLSConfigWithCertificateRecorder(config *tls.Config, recorder func(*tls.Certificate)) *tls.Config {
+	// crypto/tls calls GetCertificate only if Certificates is empty or SNI is present.
+	recConfig := config.Clone()
+	recConfig.Certificates = nil
+	getCertificate := config.GetCertificate
+	recConfig.GetCertificate = func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+		if getCertificate != nil {
+			cert, err := getCertificate(hello)
+			if err != nil {
+				return nil, err
+			}
+			if cert != nil {
+				recorder(cert)
+				return cert, nil
+			}
+		}
+		// Selects a certificate in the same way as crypto/tls
+		// when GetCertificate is not set.
+		certs := config.Certificates
+		switch len(certs) {
+		case 0:
+			return nil, nil
+		case 1:
+			recorder(&certs[0])
+			return &certs[0], nil
+		}
+		for n := range certs {
+			if hello.SupportsCertificate(&certs[n]) == nil {
+				recorder(&certs[n])
+				return &certs[n], nil
+			}
+		}
+		recorder(&certs[0])
+		return &certs[0], nil
+	}
+	return recConfig
+}
+
+// newX509CertificateFrom returns the leaf X.509 certificate of the specified TLS certificate.
+func newX509CertificateFrom(cert *tls.Certificate) (*x509.Certificate, error) {
+	if cert.Leaf != nil {
+		return cert.Leaf, nil
+	}
+	if len(cert.Certificate) == 0 {
+		return nil, NewErrNotExist("certificate")
+	}
+	return x509.ParseCertificate(cert.Certificate[0])
+}
@@ -0,0 +1,100 @@
+// Copyright (C) 2019 The go-postgresql Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package protocol
+
+// PostgreSQL: Documentation: 16: SET TRANSACTION
+// https://www.postgresql.org/docs/16/sql-set-transaction.html
+
+// IsolationLevel represents a transaction isolation level.
+type IsolationLevel int
+
+const (
+	// DefaultIsolationLevel denotes the isolation level is not specified, and the executor uses its default level.
+	DefaultIsolationLevel IsolationLevel = iota
+	// ReadUncommittedIsolationLevel represents the READ UNCOMMITTED isolation level.
+	ReadUncommittedIsolationLevel
+	// ReadCommittedIsolationLevel represents the READ COMMITTED isolation level.
+	ReadCommittedIsolationLevel
+	// RepeatableReadIsolationLevel represents the REPEATABLE READ isolation level.
+	RepeatableReadIsolationLevel
+	// SerializableIsolationLevel represents the SERIALIZABLE isolation level.
+	SerializableIsolationLevel
+)
+
+// AccessMode represents a transaction access mode.
+type AccessMode int
+
+const (
+	// DefaultAccessMode denotes the access mode is not specified, and the transaction is read/write.
+	DefaultAccessMode AccessMode = iota
+	// ReadWriteAccessMode represents the READ WRITE access mode.
+	ReadWriteAccessMode
+	// ReadOnlyAccessMode represents the READ ONLY access mode.
+	ReadOnlyAccessMode
+)
+
+// TransactionOptions represents the characteristics of a transaction.
+type TransactionOptions struct {
+	// IsolationLevel is the isolation level of the transaction.
+	IsolationLevel IsolationLevel
+	// AccessMode is the access mode of the transaction.
+	AccessMode AccessMode
+	// Deferrable is true if the serializable read-only transaction is deferrable.
+	Deferrable bool
+}
+
+// NewTransactionOptions returns the default transaction options.
+func NewTransactionOptions() TransactionOptions {
+	return TransactionOptions{
+		IsolationLevel: DefaultIsolationLevel,
+		AccessMode:     DefaultAccessMode,
+		Deferrable:     false,
+	}
+}
+
+// IsReadOnly returns true if the transaction is read-only.
+func (opts TransactionOptions) IsReadOnly() bool {
+	return opts.AccessMode == ReadOnlyAccessMode
+}
+
+// String returns the string representation of the isolation level.
+func (level IsolationLevel) String() string {
+	switch level {
+	case ReadUncommittedIsolationLevel:
+		return "READ UNCOMMITTED"
+	case ReadCommittedIsolationLevel:
+		return "READ COMMITTED"
+	case RepeatableReadIsolationLevel:
+		return "REPEATABLE READ"
+	case SerializableIsolationLevel:
+		return "SERIALIZABLE"
+	case DefaultIsolationLevel:
+		return ""
+	}
+	return ""
+}
+
+// String returns the string representation of the access mode.
+func (mode AccessMode) String() string {
+	switch mode {
+	case ReadWriteAccessMode:
+		return "READ WRITE"
+	case ReadOnlyAccessMode:
+		return "READ ONLY"
+	case DefaultAccessMode:
+		return ""
+	}
+	return ""
+}
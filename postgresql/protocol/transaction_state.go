@@ -0,0 +1,106 @@
+// Copyright (C) 2019 The go-postgresql Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package protocol
+
+// PostgreSQL: Documentation: 16: 55.7. Message Formats (ReadyForQuery)
+// https://www.postgresql.org/docs/16/protocol-message-formats.html
+// PostgreSQL: Documentation: 16: 3.4. Transactions
+// https://www.postgresql.org/docs/16/tutorial-transactions.html
+
+// TransactionState represents a transaction state of a connection.
+type TransactionState int
+
+const (
+	// TransactionIdleState denotes the connection is not in a transaction block.
+	TransactionIdleState TransactionState = iota
+	// TransactionBlockState denotes the connection is in a transaction block.
+	TransactionBlockState
+	// TransactionFailedState denotes the transaction block is aborted by an error,
+	// and the statements are rejected until the end of the transaction block.
+	TransactionFailedState
+)
+
+// TransactionCommand represents a kind of statements which changes the transaction state.
+type TransactionCommand int
+
+const (
+	// TransactionNoneCommand denotes the statements which do not control the transaction.
+	TransactionNoneCommand TransactionCommand = iota
+	// TransactionBeginCommand denotes the statements which start a transaction block such as BEGIN.
+	TransactionBeginCommand
+	// TransactionEndCommand denotes the statements which end a transaction block such as COMMIT and ROLLBACK.
+	TransactionEndCommand
+	// TransactionRollbackToCommand denotes the ROLLBACK TO SAVEPOINT statements which recover the aborted transaction block.
+	TransactionRollbackToCommand
+)
+
+// NextTransactionState returns the next state after a statement of the specified command is executed.
+func (state TransactionState) NextTransactionState(cmd TransactionCommand, hasError bool) TransactionState {
+	switch cmd {
+	case TransactionEndCommand:
+		// The transaction block ends even if COMMIT fails, because the failed transaction is rolled back.
+		return TransactionIdleState
+	case TransactionBeginCommand:
+		if state == TransactionIdleState && !hasError {
+			return TransactionBlockState
+		}
+	case TransactionRollbackToCommand:
+		if state != TransactionIdleState && !hasError {
+			return TransactionBlockState
+		}
+		if state == TransactionBlockState && hasError {
+			return TransactionFailedState
+		}
+	case TransactionNoneCommand:
+		if state == TransactionBlockState && hasError {
+			return TransactionFailedState
+		}
+	}
+	return state
+}
+
+// IsAccepted returns true if the statements of the specified command are executed in the state.
+func (state TransactionState) IsAccepted(cmd TransactionCommand) bool {
+	if state != TransactionFailedState {
+		return true
+	}
+	return cmd == TransactionEndCommand || cmd == TransactionRollbackToCommand
+}
+
+// Status returns the transaction status indicator of ReadyForQuery messages.
+func (state TransactionState) Status() TransactionStatus {
+	switch state {
+	case TransactionBlockState:
+		return TransactionBlock
+	case TransactionFailedState:
+		return TransactionFailed
+	case TransactionIdleState:
+		return TransactionIdle
+	}
+	return TransactionIdle
+}
+
+// String returns the string representation of the state.
+func (state TransactionState) String() string {
+	switch state {
+	case TransactionIdleState:
+		return "Idle"
+	case TransactionBlockState:
+		return "Block"
+	case TransactionFailedState:
+		return "Failed"
+	}
+	return ""
+}
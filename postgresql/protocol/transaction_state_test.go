@@ -0,0 +1,84 @@
+// Copyright (C) 2019 The go-postgresql Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package protocol
+
+import (
+	"testing"
+)
+
+func TestTransactionState(t *testing.T) {
+	tests := []struct {
+		state    TransactionState
+		cmd      TransactionCommand
+		hasError bool
+		expected TransactionState
+	}{
+		{TransactionIdleState, TransactionNoneCommand, false, TransactionIdleState},
+		{TransactionIdleState, TransactionNoneCommand, true, TransactionIdleState},
+		{TransactionIdleState, TransactionBeginCommand, false, TransactionBlockState},
+		{TransactionIdleState, TransactionBeginCommand, true, TransactionIdleState},
+		{TransactionBlockState, TransactionNoneCommand, false, TransactionBlockState},
+		{TransactionBlockState, TransactionNoneCommand, true, TransactionFailedState},
+		{TransactionBlockState, TransactionEndCommand, false, TransactionIdleState},
+		{TransactionBlockState, TransactionEndCommand, true, TransactionIdleState},
+		{TransactionFailedState, TransactionNoneCommand, true, TransactionFailedState},
+		{TransactionFailedState, TransactionEndCommand, false, TransactionIdleState},
+		{TransactionBlockState, TransactionRollbackToCommand, false, TransactionBlockState},
+		{TransactionBlockState, TransactionRollbackToCommand, true, TransactionFailedState},
+		{TransactionFailedState, TransactionRollbackToCommand, false, TransactionBlockState},
+		{TransactionFailedState, TransactionRollbackToCommand, true, TransactionFailedState},
+	}
+
+	for _, test := range tests {
+		next := test.state.NextTransactionState(test.cmd, test.hasError)
+		if next != test.expected {
+			t.Errorf("%s (%d, %t) : %s != %s", test.state, test.cmd, test.hasError, next, test.expected)
+		}
+	}
+
+	acceptTests := []struct {
+		state    TransactionState
+		cmd      TransactionCommand
+		expected bool
+	}{
+		{TransactionIdleState, TransactionNoneCommand, true},
+		{TransactionBlockState, TransactionNoneCommand, true},
+		{TransactionFailedState, TransactionNoneCommand, false},
+		{TransactionFailedState, TransactionBeginCommand, false},
+		{TransactionFailedState, TransactionEndCommand, true},
+		{TransactionFailedState, TransactionRollbackToCommand, true},
+	}
+
+	for _, test := range acceptTests {
+		if test.state.IsAccepted(test.cmd) != test.expected {
+			t.Errorf("%s (%d) : %t != %t", test.state, test.cmd, !test.expected, test.expected)
+		}
+	}
+
+	statusTests := []struct {
+		state    TransactionState
+		expected TransactionStatus
+	}{
+		{TransactionIdleState, TransactionIdle},
+		{TransactionBlockState, TransactionBlock},
+		{TransactionFailedState, TransactionFailed},
+	}
+
+	for _, test := range statusTests {
+		if test.state.Status() != test.expected {
+			t.Errorf("%s : %c != %c", test.state, test.state.Status(), test.expected)
+		}
+	}
+}
@@ -88,6 +88,15 @@ const (
 	CopyDoneMessage Type = 'c'
 )
 
+// IsExtendedQuery returns true if the type is a frontend message of the extended query protocol which is followed by a Sync message.
+func (t Type) IsExtendedQuery() bool {
+	switch t { // nolint:exhaustive
+	case ParseMessage, BindMessage, DescribeMessage, ExecuteMessage, CloseMessage, FlushMessage:
+		return true
+	}
+	return false
+}
+
 func (t Type) String() string {
 	switch t { // nolint:exhaustive
 	case BindMessage:
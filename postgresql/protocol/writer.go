@@ -15,89 +15,114 @@
 package protocol
 
 import (
-	"bufio"
 	"bytes"
-
-	encording "github.com/cybergarage/go-postgresql/postgresql/encoding/bytes"
+	"encoding/binary"
+	"math"
+	"sync"
 )
 
+// maxPooledWriterBufferSize is the maximum capacity of the buffers which are returned to the buffer pool.
+const maxPooledWriterBufferSize = 64 * 1024
+
+// writerBufferPool is a pool of the message buffers to reuse them for each response message.
+var writerBufferPool = sync.Pool{
+	New: func() any {
+		return &bytes.Buffer{}
+	},
+}
+
 // Writer represents a message writer.
 type Writer struct {
 	*bytes.Buffer
-	*bufio.Writer
 }
 
-// NewWriter returns a new message writer.
+// NewWriter returns a new message writer with a buffer from the buffer pool.
 func NewWriter() *Writer {
-	buffer := &bytes.Buffer{}
+	buffer, ok := writerBufferPool.Get().(*bytes.Buffer)
+	if !ok {
+		buffer = &bytes.Buffer{}
+	}
+	buffer.Reset()
 	return &Writer{
 		Buffer: buffer,
-		Writer: bufio.NewWriter(buffer),
 	}
 }
 
+// Release returns the buffer to the buffer pool. The writer must not be used after it is released.
+func (writer *Writer) Release() {
+	if writer.Buffer == nil {
+		return
+	}
+	if writer.Buffer.Cap() <= maxPooledWriterBufferSize {
+		writerBufferPool.Put(writer.Buffer)
+	}
+	writer.Buffer = nil
+}
+
 // AppendByte appends the specified byte.
 func (writer *Writer) AppendByte(c byte) error {
-	return writer.Writer.WriteByte(c)
+	return writer.Buffer.WriteByte(c)
 }
 
 // AppendBytes appends the specified bytes.
 func (writer *Writer) AppendBytes(p []byte) error {
-	_, err := writer.Writer.Write(p)
+	_, err := writer.Buffer.Write(p)
 	return err
 }
 
 // AppendInt8 appends the specified int8 value.
 func (writer *Writer) AppendInt8(v int8) error {
-	return writer.AppendBytes(encording.Int8ToBytes(v))
+	return writer.Buffer.WriteByte(byte(v))
 }
 
 // AppendInt16 appends the specified int16 value.
 func (writer *Writer) AppendInt16(v int16) error {
-	return writer.AppendBytes(encording.Int16ToBytes(v))
+	return writer.AppendBytes(binary.BigEndian.AppendUint16(writer.AvailableBuffer(), uint16(v)))
 }
 
 // AppendInt32 appends the specified int32 value.
 func (writer *Writer) AppendInt32(v int32) error {
-	return writer.AppendBytes(encording.Int32ToBytes(v))
+	return writer.AppendBytes(binary.BigEndian.AppendUint32(writer.AvailableBuffer(), uint32(v)))
 }
 
 // AppendInt64 appends the specified int64 value.
 func (writer *Writer) AppendInt64(v int64) error {
-	return writer.AppendBytes(encording.Int64ToBytes(v))
+	return writer.AppendBytes(binary.BigEndian.AppendUint64(writer.AvailableBuffer(), uint64(v)))
 }
 
 // AppendString appends the specified string.
 func (writer *Writer) AppendString(s string) error {
-	if 0 < len(s) {
-		_, err := writer.Writer.WriteString(s)
-		if err != nil {
-			return err
-		}
+	if err := writer.AppendStringBytes(s); err != nil {
+		return err
 	}
 	return writer.AppendTerminator()
 }
 
+// AppendStringBytes appends the bytes of the specified string without a null terminator.
+func (writer *Writer) AppendStringBytes(s string) error {
+	if len(s) == 0 {
+		return nil
+	}
+	_, err := writer.Buffer.WriteString(s)
+	return err
+}
+
 // AppendFloat32 appends the specified float32 value.
 func (writer *Writer) AppendFloat32(v float32) error {
-	return writer.AppendBytes(encording.Float32ToBytes(v))
+	return writer.AppendBytes(binary.BigEndian.AppendUint32(writer.AvailableBuffer(), math.Float32bits(v)))
 }
 
 // AppendFloat64 appends the specified float64 value.
 func (writer *Writer) AppendFloat64(v float64) error {
-	return writer.AppendBytes(encording.Float64ToBytes(v))
+	return writer.AppendBytes(binary.BigEndian.AppendUint64(writer.AvailableBuffer(), math.Float64bits(v)))
 }
 
 // AppendTerminator appends a null terminator.
 func (writer *Writer) AppendTerminator() error {
-	return writer.Writer.WriteByte(0x00)
+	return writer.Buffer.WriteByte(0x00)
 }
 
 // Bytes returns the message bytes.
 func (writer *Writer) Bytes() ([]byte, error) {
-	err := writer.Flush()
-	if err != nil {
-		return nil, err
-	}
 	return writer.Buffer.Bytes(), nil
 }
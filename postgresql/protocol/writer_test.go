@@ -0,0 +1,60 @@
+// Copyright (C) 2019 The go-postgresql Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package protocol
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestResponseMessageBytes(t *testing.T) {
+	msg := NewResponseMessageWith(DataRowMessage)
+	if err := msg.AppendInt16(1); err != nil {
+		t.Error(err)
+	}
+	if err := msg.AppendInt32(2); err != nil {
+		t.Error(err)
+	}
+	if err := msg.AppendString("a"); err != nil {
+		t.Error(err)
+	}
+
+	expected := []byte{'D', 0x00, 0x00, 0x00, 0x0C, 0x00, 0x01, 0x00, 0x00, 0x00, 0x02, 'a', 0x00}
+	actual, err := msg.Bytes()
+	if err != nil {
+		t.Error(err)
+	}
+	if !bytes.Equal(actual, expected) {
+		t.Errorf("Expected %v, but got %v", expected, actual)
+	}
+	msg.Release()
+
+	// A message without a type has only the length in the header.
+
+	msg = NewResponseMessage()
+	if err := msg.AppendInt32(3); err != nil {
+		t.Error(err)
+	}
+
+	expected = []byte{0x00, 0x00, 0x00, 0x08, 0x00, 0x00, 0x00, 0x03}
+	actual, err = msg.Bytes()
+	if err != nil {
+		t.Error(err)
+	}
+	if !bytes.Equal(actual, expected) {
+		t.Errorf("Expected %v, but got %v", expected, actual)
+	}
+	msg.Release()
+}
@@ -16,9 +16,48 @@ package query
 
 import (
 	"github.com/cybergarage/go-sqlparser/sql"
+	"github.com/cybergarage/go-sqlparser/sql/net"
+	"github.com/cybergarage/go-sqlparser/sql/query/response/resultset"
 )
 
 // SQLExecutor represents a SQL executor.
 type SQLExecutor interface {
 	sql.Executor
 }
+
+// SchemaExecutor represents an optional SQL executor interface to provide the table schemas.
+// The server infers the parameter data types of prepared statements from the table schemas.
+type SchemaExecutor interface {
+	// TableSchema returns the schema of the specified table in the database of the connection.
+	TableSchema(net.Conn, string) (Schema, error)
+}
+
+// DescribeExecutor represents an optional SQL executor interface to describe the result sets of queries without executing them.
+// The server returns the RowDescription of user table queries for Describe messages with the result set schemas.
+type DescribeExecutor interface {
+	// DescribeSelect returns the result set schema of the specified SELECT query.
+	DescribeSelect(net.Conn, Select) (resultset.Schema, error)
+}
+
+// SavepointExecutor represents an optional SQL executor interface to handle savepoints in transaction blocks.
+// The server manages the savepoint names of the connection, and calls the executor only for the defined savepoints.
+type SavepointExecutor interface {
+	// Savepoint should handle a SAVEPOINT statement.
+	Savepoint(net.Conn, string) error
+	// ReleaseSavepoint should handle a RELEASE SAVEPOINT statement.
+	ReleaseSavepoint(net.Conn, string) error
+	// RollbackToSavepoint should handle a ROLLBACK TO SAVEPOINT statement.
+	RollbackToSavepoint(net.Conn, string) error
+}
+
+// TwoPhaseCommitExecutor represents an optional SQL executor interface to handle two-phase commit.
+// The server manages the global transaction identifiers of the prepared transactions, and they survive the connection close,
+// so COMMIT PREPARED and ROLLBACK PREPARED may be executed by another connection.
+type TwoPhaseCommitExecutor interface {
+	// PrepareTransaction should handle a PREPARE TRANSACTION statement for the current transaction of the connection.
+	PrepareTransaction(net.Conn, string) error
+	// CommitPrepared should handle a COMMIT PREPARED statement.
+	CommitPrepared(net.Conn, string) error
+	// RollbackPrepared should handle a ROLLBACK PREPARED statement.
+	RollbackPrepared(net.Conn, string) error
+}
@@ -0,0 +1,212 @@
+// Copyright (C) 2019 The go-postgresql Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package query
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/cybergarage/go-postgresql/postgresql/system"
+	"github.com/cybergarage/go-sqlparser/sql/query"
+)
+
+// PostgreSQL: Documentation: 16: 55.2. Message Flow
+// https://www.postgresql.org/docs/16/protocol-flow.html#PROTOCOL-FLOW-EXT-QUERY
+// The ParameterDescription message describes the parameters needed by the statement,
+// and the server infers the data types of the parameters which the frontend leaves unspecified.
+
+const (
+	bindParamPrefix = "$"
+)
+
+// SchemaLookup represents a function which returns the schema of the specified table.
+type SchemaLookup func(tableName string) (Schema, bool)
+
+// TableName returns the table name of the INSERT, UPDATE, DELETE, or single table SELECT statement.
+func (stmt *Statement) TableName() (string, bool) {
+	switch stmt := stmt.obj.(type) {
+	case query.Insert:
+		return stmt.TableName(), true
+	case query.Update:
+		return stmt.TableName(), true
+	case query.Delete:
+		return stmt.TableName(), true
+	case query.Select:
+		from := stmt.From()
+		if len(from) != 1 {
+			return "", false
+		}
+		return from[0].TableName(), true
+	}
+	return "", false
+}
+
+// ParameterDataTypes returns the data types of the bind parameters in the specified query of the statements.
+// The data types which the specified data types leave unspecified are inferred from the columns of the table schema
+// which the parameters are assigned to or compared with, and the parameters which are not inferred are text.
+func ParameterDataTypes(q string, stmts []*Statement, specifiedTypes []ObjectID, lookup SchemaLookup) []ObjectID {
+	nParams := max(NumberOfBindParams(q), len(specifiedTypes))
+	if nParams == 0 {
+		return []ObjectID{}
+	}
+
+	types := make([]ObjectID, nParams)
+	copy(types, specifiedTypes)
+
+	// Infers the unspecified data types from the table schema.
+
+	for _, stmt := range stmts {
+		tableName, ok := stmt.TableName()
+		if !ok || lookup == nil {
+			continue
+		}
+		schema, ok := lookup(tableName)
+		if !ok {
+			continue
+		}
+		for idx, columnName := range stmt.bindParamColumns() {
+			if idx < 0 || nParams <= idx || types[idx] != 0 {
+				continue
+			}
+			column, err := schema.LookupColumn(columnName)
+			if err != nil {
+				continue
+			}
+			oid, err := NewObjectIDFrom(column.DataType())
+			if err != nil {
+				continue
+			}
+			types[idx] = oid
+		}
+	}
+
+	// Falls back to text when the data types are not inferred.
+
+	for n, t := range types {
+		if t == 0 {
+			types[n] = system.Text
+		}
+	}
+
+	return types
+}
+
+// bindParamColumns returns the column names which the bind parameters are assigned to or compared with, keyed by the zero-based parameter index.
+func (stmt *Statement) bindParamColumns() map[int]string {
+	paramColumns := map[int]string{}
+
+	addColumns := func(columns query.Columns) {
+		for _, column := range columns {
+			if !column.HasValue() {
+				continue
+			}
+			if idx, ok := bindParamIndex(column.Value()); ok {
+				paramColumns[idx] = column.Name()
+			}
+		}
+	}
+
+	var addExpr func(expr query.Expr)
+	addExpr = func(expr query.Expr) {
+		switch expr := expr.(type) {
+		case *query.CmpExpr:
+			right := expr.Right()
+			if right == nil || right.ValueType() == query.StringLiteral {
+				return
+			}
+			if idx, ok := bindParamIndex(right.Value()); ok {
+				paramColumns[idx] = expr.Left().Name()
+			}
+		case *query.AndExpr:
+			addExpr(expr.Left())
+			addExpr(expr.Right())
+		case *query.OrExpr:
+			addExpr(expr.Left())
+			addExpr(expr.Right())
+		}
+	}
+
+	addCondition := func(cond query.Condition) {
+		if cond == nil || !cond.HasConditions() {
+			return
+		}
+		addExpr(cond.Expr())
+	}
+
+	switch stmt := stmt.obj.(type) {
+	case query.Insert:
+		for _, columns := range stmt.Values() {
+			addColumns(columns)
+		}
+	case query.Update:
+		addColumns(stmt.Columns())
+		addCondition(stmt.Where())
+	case query.Delete:
+		addCondition(stmt.Where())
+	case query.Select:
+		addCondition(stmt.Where())
+	}
+
+	return paramColumns
+}
+
+// bindParamIndex returns the zero-based index of the specified bind parameter value such as $1.
+func bindParamIndex(v any) (int, bool) {
+	var name string
+	switch v := v.(type) {
+	case query.BindParam:
+		name = v.Name()
+	case string:
+		name = v
+	default:
+		return 0, false
+	}
+	if !strings.HasPrefix(name, bindParamPrefix) {
+		return 0, false
+	}
+	n, err := strconv.Atoi(name[len(bindParamPrefix):])
+	if err != nil || n < 1 {
+		return 0, false
+	}
+	return n - 1, true
+}
+
+// NumberOfBindParams returns the largest number of the bind parameters such as $1 in the specified query.
+// The bind parameters in quoted strings and identifiers are ignored.
+func NumberOfBindParams(q string) int {
+	nParams := 0
+	var quote byte
+	for n := 0; n < len(q); n++ {
+		c := q[n]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == bindParamPrefix[0]:
+			end := n + 1
+			for end < len(q) && '0' <= q[end] && q[end] <= '9' {
+				end++
+			}
+			if idx, err := strconv.Atoi(q[n+1 : end]); err == nil {
+				nParams = max(nParams, idx)
+			}
+			n = end - 1
+		}
+	}
+	return nParams
+}
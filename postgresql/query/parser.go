@@ -15,6 +15,9 @@
 package query
 
 import (
+	stderrors "errors"
+
+	"github.com/cybergarage/go-postgresql/postgresql/errors"
 	"github.com/cybergarage/go-sqlparser/sql"
 )
 
@@ -34,7 +37,10 @@ func NewParser() *Parser {
 func (parser *Parser) ParseString(query string) ([]*Statement, error) {
 	stmts, err := parser.Parser.ParseString(query)
 	if err != nil {
-		return nil, err
+		if stderrors.Is(err, sql.ErrEmptyQuery) {
+			return nil, err
+		}
+		return nil, errors.NewErrSyntax(err)
 	}
 	pgStmts := make([]*Statement, len(stmts))
 	for n, stmt := range stmts {
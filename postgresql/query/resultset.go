@@ -42,49 +42,96 @@ func NewRowDescriptionFromSchema(schema resultset.Schema) (*protocol.RowDescript
 }
 
 // NewResponseFromResultSet creates a response from a result set.
+// All rows of the result set are materialized, use ResultSetStream to send large result sets.
 func NewResponseFromResultSet(rs resultset.ResultSet) (protocol.Responses, error) {
-	// Schema
-
-	schema := rs.Schema()
-	if schema == nil {
-		return protocol.NewResponses(), fmt.Errorf("%w result set schema", errors.ErrInvalid)
+	stream, err := NewResultSetStreamWith(rs)
+	if err != nil {
+		return protocol.NewResponses(), err
 	}
-	selectors := schema.Selectors()
+	defer stream.Close()
 
-	// Responses
+	res := protocol.NewResponsesWith(stream.RowDescription())
+	for stream.Next() {
+		r, err := stream.Response()
+		if err != nil {
+			return nil, err
+		}
+		res = res.Append(r)
+	}
 
-	res := protocol.NewResponses()
+	return res, nil
+}
 
-	// Row description response
+// ResultSetStream represents a response stream which encodes the rows of a result set into DataRow messages
+// one by one as the result set produces them, and ends with a CommandComplete message.
+type ResultSetStream struct {
+	rs         resultset.ResultSet
+	schema     resultset.Schema
+	selectors  SelectorList
+	rowDesc    *protocol.RowDescription
+	nRows      int
+	hasRow     bool
+	isFinished bool
+}
 
+// NewResultSetStreamWith returns a new response stream of the specified result set.
+func NewResultSetStreamWith(rs resultset.ResultSet) (*ResultSetStream, error) {
+	schema := rs.Schema()
+	if schema == nil {
+		return nil, fmt.Errorf("%w result set schema", errors.ErrInvalid)
+	}
 	rowDesc, err := NewRowDescriptionFromSchema(schema)
 	if err != nil {
 		return nil, err
 	}
-	res = res.Append(rowDesc)
+	return &ResultSetStream{
+		rs:         rs,
+		schema:     schema,
+		selectors:  schema.Selectors(),
+		rowDesc:    rowDesc,
+		nRows:      0,
+		hasRow:     false,
+		isFinished: false,
+	}, nil
+}
 
-	// Data row response
+// RowDescription returns the row description of the result set.
+func (stream *ResultSetStream) RowDescription() *protocol.RowDescription {
+	return stream.rowDesc
+}
 
-	nRows := 0
-	for rs.Next() {
-		rsRow, err := rs.Row()
-		if err != nil {
-			return nil, err
-		}
-		rowObj := rsRow.Object()
-		dataRow, err := NewDataRowForSelectors(schema, rowDesc, selectors, rowObj)
-		if err != nil {
-			return nil, err
-		}
-		res = res.Append(dataRow)
-		nRows++
+// Next prepares the next DataRow message, or the CommandComplete message after all rows.
+// It returns false when no more response messages remain.
+func (stream *ResultSetStream) Next() bool {
+	if stream.isFinished {
+		return false
 	}
+	stream.hasRow = stream.rs.Next()
+	if !stream.hasRow {
+		stream.isFinished = true
+	}
+	return true
+}
 
-	cmpRes, err := protocol.NewSelectCompleteWith(nRows)
+// Response returns the current DataRow message, or the CommandComplete message after all rows.
+func (stream *ResultSetStream) Response() (protocol.Response, error) {
+	if !stream.hasRow {
+		return protocol.NewSelectCompleteWith(stream.nRows)
+	}
+	rsRow, err := stream.rs.Row()
+	if err != nil {
+		return nil, err
+	}
+	dataRow, err := NewDataRowForSelectors(stream.schema, stream.rowDesc, stream.selectors, rsRow.Object())
 	if err != nil {
 		return nil, err
 	}
-	res = res.Append(cmpRes)
+	stream.nRows++
+	return dataRow, nil
+}
 
-	return res, nil
+// Close closes the result set.
+func (stream *ResultSetStream) Close() error {
+	stream.isFinished = true
+	return stream.rs.Close()
 }
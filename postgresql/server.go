@@ -44,9 +44,28 @@ type ErrorHandler interface {
 	ParserError(Conn, string, error) (protocol.Responses, error)
 }
 
+// AuthMethodPolicy represents a policy which decides the client authentication method per connection
+// from the start-up parameters, the remote address and the TLS state of the connection.
+type AuthMethodPolicy interface {
+	// AuthenticationMethod returns the authentication method for the connection of the start-up message.
+	AuthenticationMethod(Conn, *protocol.Startup) (auth.Method, error)
+}
+
 // SQLExecutor represents a SQL executor.
 type SQLExecutor = query.SQLExecutor
 
+// SchemaExecutor represents an optional SQL executor interface to provide the table schemas.
+type SchemaExecutor = query.SchemaExecutor
+
+// DescribeExecutor represents an optional SQL executor interface to describe the result sets of queries without executing them.
+type DescribeExecutor = query.DescribeExecutor
+
+// SavepointExecutor represents an optional SQL executor interface to handle savepoints in transaction blocks.
+type SavepointExecutor = query.SavepointExecutor
+
+// TwoPhaseCommitExecutor represents an optional SQL executor interface to handle two-phase commit.
+type TwoPhaseCommitExecutor = query.TwoPhaseCommitExecutor
+
 // SQLExecutorSetter represents a SQL executor setter.
 type SQLExecutorSetter interface {
 	SetSQLExecutor(SQLExecutor)
@@ -71,6 +90,8 @@ type Server interface {
 	SetBulkQueryExecutor(BulkQueryExecutor)
 	// SetErrorHandler sets a user error handler.
 	SetErrorHandler(ErrorHandler)
+	// SetAuthMethodPolicy sets a client authentication method policy.
+	SetAuthMethodPolicy(AuthMethodPolicy)
 
 	// SQLExecutor returns a SQL executor.
 	SQLExecutor() SQLExecutor
@@ -84,6 +105,8 @@ type Server interface {
 	BulkQueryExecutor() BulkQueryExecutor
 	// ErrorHandler returns a user error handler.
 	ErrorHandler() ErrorHandler
+	// AuthMethodPolicy returns the client authentication method policy.
+	AuthMethodPolicy() AuthMethodPolicy
 
 	// Start starts the server.
 	Start() error
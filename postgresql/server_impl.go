@@ -24,7 +24,6 @@ import (
 // server represents a PostgreSQL protocol server.
 type server struct {
 	protocol.Server
-	*protocolStartupHandler
 	*protocolQueryHandler
 
 	sqlExecutor         SQLExecutor
@@ -33,22 +32,23 @@ type server struct {
 	exQueryExecutor     ExQueryExecutor
 	bulkQueryExecutor   BulkQueryExecutor
 	errorHandler        ErrorHandler
+	authMethodPolicy    AuthMethodPolicy
 	authManager         auth.Manager
 }
 
 // NewServer returns a new server instance.
 func NewServer() Server {
 	server := &server{
-		Server:                 protocol.NewServer(),
-		protocolStartupHandler: newProtocolStartupHandler(),
-		protocolQueryHandler:   newProtocolQueryHandler(),
-		sqlExecutor:            nil,
-		queryExecutor:          NewDefaultQueryExecutor(),
-		exQueryExecutor:        nil,
-		bulkQueryExecutor:      NewNullBulkExecutor(),
-		errorHandler:           NewNullErrorHandler(),
-		systemQueryExecutor:    NewNullSystemQueryExecutor(),
-		authManager:            auth.NewManager(),
+		Server:               protocol.NewServer(),
+		protocolQueryHandler: newProtocolQueryHandler(),
+		sqlExecutor:          nil,
+		queryExecutor:        NewDefaultQueryExecutor(),
+		exQueryExecutor:      nil,
+		bulkQueryExecutor:    NewNullBulkExecutor(),
+		errorHandler:         NewNullErrorHandler(),
+		systemQueryExecutor:  NewNullSystemQueryExecutor(),
+		authMethodPolicy:     nil,
+		authManager:          auth.NewManager(),
 	}
 
 	server.exQueryExecutor = NewDefaultExQueryExecutorWith(
@@ -110,6 +110,12 @@ func (server *server) SetErrorHandler(eh ErrorHandler) {
 	server.errorHandler = eh
 }
 
+// SetAuthMethodPolicy sets a client authentication method policy.
+// The authentication method of the configuration is used for all connections if no policy is set.
+func (server *server) SetAuthMethodPolicy(p AuthMethodPolicy) {
+	server.authMethodPolicy = p
+}
+
 // SetSystemQueryExecutor sets a system query server.
 func (server *server) SetSystemQueryExecutor(sq SystemQueryExecutor) {
 	server.systemQueryExecutor = sq
@@ -145,6 +151,11 @@ func (server *server) ErrorHandler() ErrorHandler {
 	return server.errorHandler
 }
 
+// AuthMethodPolicy returns the client authentication method policy.
+func (server *server) AuthMethodPolicy() AuthMethodPolicy {
+	return server.authMethodPolicy
+}
+
 // Start starts the server.
 func (server *server) Start() error {
 	type starter interface {
@@ -17,6 +17,7 @@ package postgresql
 import (
 	stderrors "errors"
 
+	"github.com/cybergarage/go-postgresql/postgresql/errors"
 	"github.com/cybergarage/go-postgresql/postgresql/protocol"
 	"github.com/cybergarage/go-postgresql/postgresql/query"
 	"github.com/cybergarage/go-postgresql/postgresql/stmt"
@@ -24,17 +25,20 @@ import (
 	sqlparser "github.com/cybergarage/go-sqlparser/sql/parser"
 	sql "github.com/cybergarage/go-sqlparser/sql/query"
 	"github.com/cybergarage/go-sqlparser/sql/query/response/resultset"
+	sqlstmt "github.com/cybergarage/go-sqlparser/sql/stmt"
 )
 
 // protocolQueryHandler represents a protocol query server.
 type protocolQueryHandler struct {
 	*stmt.PreparedManager
+	preparedXacts *stmt.PreparedTransactionManager
 }
 
 // newProtocolQueryHandlerWith returns a new protocol query server.
 func newProtocolQueryHandler() *protocolQueryHandler {
 	return &protocolQueryHandler{
 		PreparedManager: stmt.NewPreparedManager(),
+		preparedXacts:   stmt.NewPreparedTransactionManager(),
 	}
 }
 
@@ -44,6 +48,16 @@ func (server *server) Parse(conn Conn, msg *protocol.Parse) (protocol.Responses,
 	if err != nil {
 		return nil, err
 	}
+
+	// Infers the parameter data types which the client leaves unspecified for ParameterDescription.
+
+	prepStmt, err := server.PreparedStatement(conn, msg.Name)
+	if err != nil {
+		return nil, err
+	}
+	prepStmt.DataTypes = query.ParameterDataTypes(msg.Query, prepStmt.ParsedStatements, msg.DataTypes, server.tableSchemaLookup(conn))
+	prepStmt.NumDataTypes = int16(len(prepStmt.DataTypes))
+
 	return protocol.NewResponsesWith(protocol.NewParseComplete()), nil
 }
 
@@ -59,7 +73,7 @@ func (server *server) Bind(conn Conn, msg *protocol.Bind) (protocol.Responses, e
 		return nil, err
 	}
 
-	err = server.SetPreparedPortal(conn, msg.PortalName, q)
+	err = server.SetPreparedPortal(conn, msg.PortalName, stmt.NewPreparedPortalWith(q))
 	if err != nil {
 		return nil, err
 	}
@@ -69,31 +83,22 @@ func (server *server) Bind(conn Conn, msg *protocol.Bind) (protocol.Responses, e
 
 // Describe handles a describe protocol.
 func (server *server) Describe(conn Conn, msg *protocol.Describe) (protocol.Responses, error) {
-	isSystemSelect := func(stmt query.Select) bool {
-		from := stmt.From()
-		if len(from) == 0 {
-			return true
-		}
-		if from.HasSchemaTable(system.SystemSchemaNames...) {
-			return true
-		}
-		return false
-	}
-
-	newRowDescriptionResponse := func(schema resultset.Schema) (protocol.Responses, error) {
+	newRowDescriptionResponse := func(schema resultset.Schema, resFmts protocol.FormatCodes) (protocol.Responses, error) {
 		rowDesc, err := query.NewRowDescriptionFromSchema(schema)
 		if err != nil {
 			return nil, err
 		}
+		rowDesc.SetResultFormats(resFmts)
 		return protocol.NewResponsesWith(rowDesc), nil
 	}
 
-	newPortalDescribeResponses := func(stmt query.Select) (protocol.Responses, error) {
-		if isSystemSelect(stmt) {
-			schema, err := system.NewSchemaForSelect(stmt)
-			if err == nil {
-				return newRowDescriptionResponse(schema)
-			}
+	newPortalDescribeResponses := func(stmt query.Select, resFmts protocol.FormatCodes) (protocol.Responses, error) {
+		schema, ok, err := server.describeSelect(conn, stmt)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return newRowDescriptionResponse(schema, resFmts)
 		}
 		return protocol.NewResponsesWith(protocol.NewNoData()), nil
 	}
@@ -103,15 +108,16 @@ func (server *server) Describe(conn Conn, msg *protocol.Describe) (protocol.Resp
 		if err != nil {
 			return nil, err
 		}
-		if isSystemSelect(stmt) {
-			schema, err := system.NewSchemaForSelect(stmt)
-			if err == nil {
-				rowDesc, err := query.NewRowDescriptionFromSchema(schema)
-				if err != nil {
-					return nil, err
-				}
-				return protocol.NewResponsesWith(paramDesc, rowDesc), nil
+		schema, ok, err := server.describeSelect(conn, stmt)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			rowDesc, err := query.NewRowDescriptionFromSchema(schema)
+			if err != nil {
+				return nil, err
 			}
+			return protocol.NewResponsesWith(paramDesc, rowDesc), nil
 		}
 		return protocol.NewResponsesWith(paramDesc, protocol.NewNoData()), nil
 	}
@@ -122,9 +128,12 @@ func (server *server) Describe(conn Conn, msg *protocol.Describe) (protocol.Resp
 		if err != nil {
 			return nil, err
 		}
-		switch stmt := prepStmt.ParsedStatement.Object().(type) {
-		case query.Select:
-			return newStatementDescribeResponses(prepStmt, stmt)
+		// The prepared statement which has multiple statements has no single row description.
+		if len(prepStmt.ParsedStatements) == 1 {
+			switch stmt := prepStmt.ParsedStatement.Object().(type) {
+			case query.Select:
+				return newStatementDescribeResponses(prepStmt, stmt)
+			}
 		}
 		paramDesc, err := protocol.NewParameterDescriptionWith(prepStmt.DataTypes...)
 		if err != nil {
@@ -138,6 +147,9 @@ func (server *server) Describe(conn Conn, msg *protocol.Describe) (protocol.Resp
 		if err != nil {
 			return nil, err
 		}
+		if stmt.HasUnparsedCommand(prepPortal.Query.Query) {
+			return protocol.NewResponsesWith(protocol.NewNoData()), nil
+		}
 		stmts, err := prepPortal.Statements()
 		if err != nil {
 			return nil, err
@@ -147,7 +159,7 @@ func (server *server) Describe(conn Conn, msg *protocol.Describe) (protocol.Resp
 		}
 		switch stmt := stmts[0].(type) {
 		case query.Select:
-			return newPortalDescribeResponses(stmt)
+			return newPortalDescribeResponses(stmt, prepPortal.ResultFormats)
 		default:
 			return protocol.NewResponsesWith(protocol.NewNoData()), nil
 		}
@@ -157,12 +169,72 @@ func (server *server) Describe(conn Conn, msg *protocol.Describe) (protocol.Resp
 
 // Execute handles a execute protocol.
 func (server *server) Execute(conn Conn, msg *protocol.Execute) (protocol.Responses, error) {
-	q, err := server.PreparedPortal(conn, msg.PortalName)
+	portal, err := server.PreparedPortal(conn, msg.PortalName)
+	if err != nil {
+		return nil, err
+	}
+
+	// PostgreSQL: Documentation: 16: 55.2. Message Flow
+	// https://www.postgresql.org/docs/16/protocol-flow.html#PROTOCOL-FLOW-EXT-QUERY
+	// If Execute terminates before completing the execution of a portal (due to reaching a nonzero result-row count),
+	// it will send a PortalSuspended message. The frontend can then issue another Execute message
+	// for the same portal to continue the operation.
+
+	fetchPortal := func(cursor *stmt.PortalCursor) error {
+		res, suspended, err := cursor.Fetch(int(msg.MaxRows))
+		if !suspended {
+			portal.CloseCursor()
+		}
+		if err != nil {
+			return err
+		}
+		return conn.ResponseMessages(res)
+	}
+
+	// Rejects the portal except the end of the transaction block in an aborted transaction block.
+
+	stmts, err := portal.Statements()
+	if err == nil {
+		for _, stmt := range stmts {
+			if !conn.TransactionState().IsAccepted(transactionCommandOf(stmt)) {
+				return nil, errors.NewErrInFailedTransaction()
+			}
+		}
+	}
+
+	if cursor, ok := portal.Cursor(); ok {
+		return nil, fetchPortal(cursor)
+	}
+
+	if msg.MaxRows <= 0 {
+		return server.executeQuery(conn, portal.Query, false)
+	}
+
+	if err != nil || len(stmts) != 1 || stmts[0].StatementType() != sql.SelectStatement {
+		return server.executeQuery(conn, portal.Query, false)
+	}
+
+	// Opens the cursor with the result set stream if the executor supports streaming.
+
+	selectStmt, _ := stmts[0].(query.Select)
+	resStream, ok, err := server.selectStream(conn, selectStmt)
 	if err != nil {
 		return nil, err
 	}
+	if ok {
+		resStream.RowDescription().SetResultFormats(portal.ResultFormats)
+		return nil, fetchPortal(portal.OpenCursor(resStream))
+	}
 
-	return server.executeQuery(conn, q, false)
+	res, err := server.executeStatement(conn, stmts[0], portal.ResultFormats, false)
+	if err != nil || res.HasErrorResponse() {
+		if 0 < len(res) {
+			err = conn.ResponseMessages(res)
+		}
+		return nil, err
+	}
+
+	return nil, fetchPortal(portal.OpenCursor(protocol.NewResponseStreamWith(res)))
 }
 
 // Close handles a close protocol.
@@ -196,7 +268,7 @@ func (server *server) Flush(conn Conn, msg *protocol.Flush) (protocol.Responses,
 	// https://www.postgresql.org/docs/16/protocol-flow.html
 	// The Flush message does not cause any specific output to be generated,
 	// but forces the backend to deliver any data pending in its output buffers.
-	return nil, nil
+	return nil, conn.Flush()
 }
 
 // Query handles a query protocol.
@@ -205,21 +277,459 @@ func (server *server) Query(conn Conn, msg *protocol.Query) (protocol.Responses,
 }
 
 func (server *server) executeQuery(conn Conn, msg *protocol.Query, sendRowDescription bool) (protocol.Responses, error) {
+	res, _, err := server.executeQueryStatements(conn, msg, sendRowDescription)
+	return res, err
+}
+
+// executeQueryStatements executes the statements of the specified query, and returns the responses which are not sent yet.
+// It returns false if a statement fails with an error response which has been already sent.
+func (server *server) executeQueryStatements(conn Conn, msg *protocol.Query, sendRowDescription bool) (protocol.Responses, bool, error) {
+	// The SQL parser does not support the SQL-level prepared statement commands, the savepoint commands,
+	// and the transaction modes, so they are handled before parsing.
+	if stmt.HasUnparsedCommand(msg.Query) {
+		ok, err := server.executeCommands(conn, msg, sendRowDescription)
+		return nil, ok, err
+	}
+
 	conn.StartSpan("parse")
 	stmts, err := msg.Statements()
 	conn.FinishSpan()
 	if err != nil {
 		// Is it a empty query for ping?
 		if stderrors.Is(err, sqlparser.ErrEmptyQuery) {
-			return protocol.NewEmptyCompleteResponses()
+			res, err := protocol.NewEmptyCompleteResponses()
+			return res, true, err
 		}
 		res, err := server.errorHandler.ParserError(conn, msg.String(), err)
 		if err != nil {
-			return nil, err
+			return nil, false, err
+		}
+		return res, true, nil
+	}
+
+	// PostgreSQL: Documentation: 16: 55.2.2.1. Multiple Statements in a Simple Query
+	// https://www.postgresql.org/docs/16/protocol-flow.html#PROTOCOL-FLOW-MULTI-STATEMENT
+	// When a simple Query message contains more than one SQL statement, they are treated as a single transaction,
+	// unless explicit transaction control commands are included to force a different behavior.
+
+	if !isImplicitTransaction(conn, stmts) {
+		ok, err := server.executeStatements(conn, msg, stmts, sendRowDescription)
+		return nil, ok, err
+	}
+
+	ok, err := server.executeImplicitTransaction(conn, func() (bool, error) {
+		return server.executeStatements(conn, msg, stmts, sendRowDescription)
+	})
+	return nil, ok, err
+}
+
+// executeImplicitTransaction executes the specified function in an implicit transaction.
+// The transaction is committed if the function succeeds, otherwise it is rolled back.
+// It returns false if a statement fails with an error response which has been already sent.
+func (server *server) executeImplicitTransaction(conn Conn, execute func() (bool, error)) (bool, error) {
+	if _, err := server.queryExecutor.Begin(conn, sql.NewBegin()); err != nil {
+		return false, err
+	}
+
+	ok, err := execute()
+	if !ok || err != nil {
+		if _, rbErr := server.queryExecutor.Rollback(conn, sql.NewRollback()); rbErr != nil && err == nil {
+			return false, rbErr
+		}
+		return ok, err
+	}
+
+	if _, err := server.queryExecutor.Commit(conn, sql.NewCommit()); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// executeStatements executes the specified statements one by one, and stops at the first failed statement.
+// It returns false if a statement fails with an error response which has been already sent.
+func (server *server) executeStatements(conn Conn, msg *protocol.Query, stmts []sqlstmt.Statement, sendRowDescription bool) (bool, error) {
+	for _, stmt := range stmts {
+		// Stops the remaining statements if the query is canceled by a CancelRequest.
+		if err := conn.Context().Err(); err != nil {
+			return false, errors.NewErrQueryCanceled(err)
+		}
+
+		// PostgreSQL: Documentation: 16: 55.2.2. Simple Query
+		// https://www.postgresql.org/docs/16/protocol-flow.html#PROTOCOL-FLOW-SIMPLE-QUERY
+		// The statements in an aborted transaction block are rejected until the end of the transaction block.
+
+		txCmd := transactionCommandOf(stmt)
+		if !conn.TransactionState().IsAccepted(txCmd) {
+			return false, errors.NewErrInFailedTransaction()
+		}
+
+		res, err := server.executeStatement(conn, stmt, msg.ResultFormats, sendRowDescription)
+		hasError := err != nil || res.HasErrorResponse()
+		conn.SetTransactionState(conn.TransactionState().NextTransactionState(txCmd, hasError))
+		if 0 < len(res) {
+			if err := conn.ResponseMessages(res); err != nil {
+				return false, err
+			}
+		}
+		if err != nil {
+			return false, err
+		}
+		// The remaining statements are not executed after an error response.
+		if hasError {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// isImplicitTransaction returns true if the specified statements should be executed in an implicit transaction.
+func isImplicitTransaction(conn Conn, stmts []sqlstmt.Statement) bool {
+	if len(stmts) < 2 || conn.TransactionState() != protocol.TransactionIdleState {
+		return false
+	}
+	for _, stmt := range stmts {
+		if transactionCommandOf(stmt) != protocol.TransactionNoneCommand {
+			return false
+		}
+	}
+	return true
+}
+
+// isImplicitCommandTransaction returns true if the specified queries which have the commands unsupported by the SQL parser
+// should be executed in an implicit transaction.
+func isImplicitCommandTransaction(conn Conn, queries []string) bool {
+	if len(queries) < 2 || conn.TransactionState() != protocol.TransactionIdleState {
+		return false
+	}
+	for _, q := range queries {
+		if stmt.IsTransactionControlCommand(q) {
+			return false
+		}
+	}
+	return true
+}
+
+// executeCommands executes the statements of the specified query which has the commands unsupported by the SQL parser.
+// The statements are executed in an implicit transaction as well as the statements parsed by the SQL parser.
+// It returns false if a statement fails with an error response which has been already sent.
+func (server *server) executeCommands(conn Conn, msg *protocol.Query, sendRowDescription bool) (bool, error) {
+	queries := stmt.SplitQueries(msg.Query)
+	if !isImplicitCommandTransaction(conn, queries) {
+		return server.executeCommandQueries(conn, queries, sendRowDescription)
+	}
+	return server.executeImplicitTransaction(conn, func() (bool, error) {
+		return server.executeCommandQueries(conn, queries, sendRowDescription)
+	})
+}
+
+// executeCommandQueries executes the specified queries one by one, and stops at the first failed statement.
+// The prepared statements share the namespace of the prepared statements of the extended query protocol.
+// It returns false if a statement fails with an error response which has been already sent.
+func (server *server) executeCommandQueries(conn Conn, queries []string, sendRowDescription bool) (bool, error) {
+	executeQuery := func(q *protocol.Query) (bool, error) {
+		res, ok, err := server.executeQueryStatements(conn, q, sendRowDescription)
+		if err != nil {
+			return false, err
+		}
+		// The error responses such as parser errors abort the transaction block as well as the failed statements.
+		hasError := res.HasErrorResponse()
+		if hasError {
+			conn.SetTransactionState(conn.TransactionState().NextTransactionState(protocol.TransactionNoneCommand, hasError))
+		}
+		if err := conn.ResponseMessages(res); err != nil {
+			return false, err
 		}
-		return res, nil
+		return ok && !hasError, nil
 	}
 
+	for _, q := range queries {
+		// Stops the remaining statements if the query is canceled by a CancelRequest.
+		if err := conn.Context().Err(); err != nil {
+			return false, errors.NewErrQueryCanceled(err)
+		}
+
+		if stmt.IsSavepointCommand(q) {
+			if err := server.executeSavepointCommand(conn, q); err != nil {
+				return false, err
+			}
+			continue
+		}
+
+		if stmt.IsTransactionModeCommand(q) {
+			if ok, err := server.executeTransactionModeCommand(conn, q); !ok || err != nil {
+				return false, err
+			}
+			continue
+		}
+
+		if stmt.IsPreparedTransactionCommand(q) {
+			if err := server.executePreparedTransactionCommand(conn, q); err != nil {
+				return false, err
+			}
+			continue
+		}
+
+		if !stmt.IsPreparedCommand(q) {
+			if ok, err := executeQuery(protocol.NewQueryWithString(q)); !ok || err != nil {
+				return false, err
+			}
+			continue
+		}
+
+		if !conn.TransactionState().IsAccepted(protocol.TransactionNoneCommand) {
+			return false, errors.NewErrInFailedTransaction()
+		}
+
+		cmd, err := stmt.NewPreparedCommandFrom(q)
+		if err != nil {
+			return false, err
+		}
+
+		switch cmd.Type {
+		case stmt.PrepareCommand:
+			if _, err := server.PreparedStatement(conn, cmd.Name); err == nil {
+				return false, errors.NewErrPreparedStatementExist(cmd.Name)
+			}
+			if _, err := server.Parse(conn, cmd.NewParse()); err != nil {
+				return false, err
+			}
+		case stmt.ExecuteCommand:
+			prepStmt, err := server.PreparedStatement(conn, cmd.Name)
+			if err != nil {
+				return false, err
+			}
+			if len(cmd.Params) != len(prepStmt.DataTypes) {
+				return false, errors.NewErrPreparedStatementParamsNotEqual(cmd.Name, len(prepStmt.DataTypes), len(cmd.Params))
+			}
+			execQuery, err := protocol.NewQueryWith(prepStmt.Parse, cmd.NewBind())
+			if err != nil {
+				return false, err
+			}
+			if ok, err := executeQuery(execQuery); !ok || err != nil {
+				return false, err
+			}
+			continue
+		case stmt.DeallocateCommand:
+			if cmd.IsAll {
+				server.RemoveAllPreparedStatements(conn)
+			} else if err := server.RemovePreparedStatement(conn, cmd.Name); err != nil {
+				return false, err
+			}
+		}
+
+		res, err := protocol.NewCommandCompleteResponsesWith(cmd.CommandTag())
+		if err != nil {
+			return false, err
+		}
+		if err := conn.ResponseMessages(res); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// executeSavepointCommand executes the specified savepoint command with the SavepointExecutor.
+// The savepoints of the connection are updated only if the executor succeeds.
+func (server *server) executeSavepointCommand(conn Conn, q string) error {
+	cmd, err := stmt.NewSavepointCommandFrom(q)
+	if err != nil {
+		return err
+	}
+
+	txCmd := cmd.TransactionCommand()
+	txState := conn.TransactionState()
+	if !txState.IsAccepted(txCmd) {
+		return errors.NewErrInFailedTransaction()
+	}
+	if txState == protocol.TransactionIdleState {
+		return errors.NewErrNoActiveTransaction(cmd.String())
+	}
+
+	executor, ok := server.savepointExecutor()
+	if !ok {
+		return errors.NewErrNotImplemented(cmd.String())
+	}
+
+	savepoints := conn.Savepoints()
+	switch cmd.Type {
+	case stmt.DefineSavepointCommand:
+		if err := executor.Savepoint(conn, cmd.Name); err != nil {
+			return err
+		}
+		savepoints.Push(cmd.Name)
+	case stmt.ReleaseSavepointCommand:
+		if !savepoints.Contains(cmd.Name) {
+			return errors.NewErrSavepointNotExist(cmd.Name)
+		}
+		if err := executor.ReleaseSavepoint(conn, cmd.Name); err != nil {
+			return err
+		}
+		savepoints.Release(cmd.Name)
+	case stmt.RollbackToSavepointCommand:
+		if !savepoints.Contains(cmd.Name) {
+			return errors.NewErrSavepointNotExist(cmd.Name)
+		}
+		if err := executor.RollbackToSavepoint(conn, cmd.Name); err != nil {
+			return err
+		}
+		savepoints.RollbackTo(cmd.Name)
+	}
+
+	// ROLLBACK TO SAVEPOINT recovers the aborted transaction block.
+	conn.SetTransactionState(txState.NextTransactionState(txCmd, false))
+
+	res, err := protocol.NewCommandCompleteResponsesWith(cmd.CommandTag())
+	if err != nil {
+		return err
+	}
+	return conn.ResponseMessages(res)
+}
+
+// executeTransactionModeCommand executes the specified BEGIN, START TRANSACTION, or SET TRANSACTION command with transaction modes.
+// The characteristics are set to the connection before BEGIN, so the executors can read them with Conn.TransactionOptions().
+// It returns false if BEGIN fails with an error response which has been already sent.
+func (server *server) executeTransactionModeCommand(conn Conn, q string) (bool, error) {
+	cmd, err := stmt.NewTransactionModeCommandFrom(q)
+	if err != nil {
+		return false, err
+	}
+
+	txCmd := cmd.TransactionCommand()
+	txState := conn.TransactionState()
+	if !txState.IsAccepted(txCmd) {
+		return false, errors.NewErrInFailedTransaction()
+	}
+
+	switch cmd.Type {
+	case stmt.BeginTransactionCommand:
+		if txState == protocol.TransactionIdleState {
+			conn.SetTransactionOptions(cmd.Apply(protocol.NewTransactionOptions()))
+		}
+		res, err := server.executeStatement(conn, sql.NewBegin(), nil, false)
+		// The characteristics are reset if BEGIN fails.
+		hasError := err != nil || res.HasErrorResponse()
+		conn.SetTransactionState(txState.NextTransactionState(txCmd, hasError))
+		if 0 < len(res) {
+			if err := conn.ResponseMessages(res); err != nil {
+				return false, err
+			}
+		}
+		return !hasError, err
+	case stmt.SetTransactionCommand:
+		if txState == protocol.TransactionIdleState {
+			if err := conn.ResponseNotice(errors.NewWarningNoActiveTransaction()); err != nil {
+				return false, err
+			}
+		} else {
+			conn.SetTransactionOptions(cmd.Apply(conn.TransactionOptions()))
+		}
+	}
+
+	res, err := protocol.NewCommandCompleteResponsesWith(cmd.CommandTag())
+	if err != nil {
+		return false, err
+	}
+	return true, conn.ResponseMessages(res)
+}
+
+// executePreparedTransactionCommand executes the specified PREPARE TRANSACTION, COMMIT PREPARED, or ROLLBACK PREPARED command
+// with the TwoPhaseCommitExecutor. The prepared transactions are registered in the server until they are finished.
+func (server *server) executePreparedTransactionCommand(conn Conn, q string) error {
+	cmd, err := stmt.NewPreparedTransactionCommandFrom(q)
+	if err != nil {
+		return err
+	}
+
+	txState := conn.TransactionState()
+	tag := cmd.CommandTag()
+
+	switch cmd.Type {
+	case stmt.PrepareTransactionCommand:
+		// PREPARE TRANSACTION ends the transaction block, and the aborted transaction block is rolled back.
+		switch txState {
+		case protocol.TransactionIdleState:
+			res, err := server.responseTransactionWarning(conn, errors.NewWarningNoActiveTransaction(), sql.NewRollback())
+			if err != nil {
+				return err
+			}
+			return conn.ResponseMessages(res)
+		case protocol.TransactionFailedState:
+			res, err := server.queryExecutor.Rollback(conn, sql.NewRollback())
+			conn.SetTransactionState(protocol.TransactionIdleState)
+			if err != nil {
+				return err
+			}
+			return conn.ResponseMessages(res)
+		case protocol.TransactionBlockState:
+			// The transaction is rolled back if it cannot be prepared.
+			if err := server.prepareTransaction(conn, cmd.GID); err != nil {
+				_, _ = server.queryExecutor.Rollback(conn, sql.NewRollback())
+				conn.SetTransactionState(protocol.TransactionIdleState)
+				return err
+			}
+			conn.SetTransactionState(protocol.TransactionIdleState)
+		}
+	case stmt.CommitPreparedCommand, stmt.RollbackPreparedCommand:
+		if !txState.IsAccepted(protocol.TransactionNoneCommand) {
+			return errors.NewErrInFailedTransaction()
+		}
+		if txState != protocol.TransactionIdleState {
+			return errors.NewErrActiveTransaction(cmd.String())
+		}
+		if err := server.finishPreparedTransaction(conn, cmd); err != nil {
+			return err
+		}
+	}
+
+	res, err := protocol.NewCommandCompleteResponsesWith(tag)
+	if err != nil {
+		return err
+	}
+	return conn.ResponseMessages(res)
+}
+
+// prepareTransaction prepares the current transaction of the connection with the specified global transaction identifier.
+func (server *server) prepareTransaction(conn Conn, gid string) error {
+	executor, ok := server.twoPhaseCommitExecutor()
+	if !ok {
+		return errors.NewErrNotImplemented("PREPARE TRANSACTION")
+	}
+	if _, err := server.preparedXacts.ReservePreparedTransaction(conn, gid); err != nil {
+		return err
+	}
+	if err := executor.PrepareTransaction(conn, gid); err != nil {
+		_, _ = server.preparedXacts.TakePreparedTransaction(gid)
+		return err
+	}
+	return nil
+}
+
+// finishPreparedTransaction commits or rolls back the prepared transaction of the specified command.
+func (server *server) finishPreparedTransaction(conn Conn, cmd *stmt.PreparedTransactionCommand) error {
+	executor, ok := server.twoPhaseCommitExecutor()
+	if !ok {
+		return errors.NewErrNotImplemented(cmd.String())
+	}
+	xact, err := server.preparedXacts.TakePreparedTransaction(cmd.GID)
+	if err != nil {
+		return err
+	}
+	if cmd.Type == stmt.CommitPreparedCommand {
+		err = executor.CommitPrepared(conn, cmd.GID)
+	} else {
+		err = executor.RollbackPrepared(conn, cmd.GID)
+	}
+	if err != nil {
+		server.preparedXacts.RestorePreparedTransaction(xact)
+		return err
+	}
+	return nil
+}
+
+// executeStatement executes the specified statement and returns the responses without sending them.
+// The rows of SELECT queries are encoded in the specified result-column format codes.
+func (server *server) executeStatement(conn Conn, stmt sqlstmt.Statement, resFmts protocol.FormatCodes, sendRowDescription bool) (protocol.Responses, error) {
 	handleCopyQuery := func(conn Conn, stmt query.Copy) (protocol.Responses, error) {
 		res, err := server.bulkQueryExecutor.Copy(conn, stmt)
 		if err != nil || res.HasErrorResponse() {
@@ -238,101 +748,280 @@ func (server *server) executeQuery(conn Conn, msg *protocol.Query, sendRowDescri
 		return server.bulkQueryExecutor.CopyData(conn, stmt, NewCopyStreamWithReader(conn.MessageReader()))
 	}
 
-	for _, stmt := range stmts {
-		var res protocol.Responses
-
-		// nolint: forcetypeassert
-		switch stmt.StatementType() {
-		case sql.BeginStatement:
-			err = conn.LockTransaction()
-			if err == nil {
-				stmt := stmt.(query.Begin)
-				res, err = server.queryExecutor.Begin(conn, stmt)
-			}
-		case sql.CommitStatement:
-			stmt := stmt.(query.Commit)
+	var res protocol.Responses
+	var err error
+
+	// PostgreSQL: Documentation: 16: SET TRANSACTION
+	// https://www.postgresql.org/docs/16/sql-set-transaction.html
+	// The statements which modify data are rejected in read-only transactions before they reach the executors.
+
+	if conn.TransactionOptions().IsReadOnly() && isWriteStatement(stmt) {
+		return nil, errors.NewErrReadOnlyTransaction(stmt.StatementType().String())
+	}
+
+	// nolint: forcetypeassert
+	switch stmt.StatementType() {
+	case sql.BeginStatement:
+		stmt := stmt.(query.Begin)
+		if conn.TransactionState() != protocol.TransactionIdleState {
+			return server.responseTransactionWarning(conn, errors.NewWarningActiveTransaction(), stmt)
+		}
+		res, err = server.queryExecutor.Begin(conn, stmt)
+	case sql.CommitStatement:
+		stmt := stmt.(query.Commit)
+		switch conn.TransactionState() {
+		case protocol.TransactionIdleState:
+			return server.responseTransactionWarning(conn, errors.NewWarningNoActiveTransaction(), stmt)
+		case protocol.TransactionFailedState:
+			// COMMIT of an aborted transaction block rolls back the transaction.
+			res, err = server.queryExecutor.Rollback(conn, sql.NewRollback())
+		case protocol.TransactionBlockState:
 			res, err = server.queryExecutor.Commit(conn, stmt)
-			conn.UnlockTransaction()
-		case sql.RollbackStatement:
-			stmt := stmt.(query.Rollback)
-			res, err = server.queryExecutor.Rollback(conn, stmt)
-			conn.UnlockTransaction()
-		case sql.CreateDatabaseStatement:
-			stmt := stmt.(query.CreateDatabase)
-			res, err = server.queryExecutor.CreateDatabase(conn, stmt)
-		case sql.CreateTableStatement:
-			stmt := stmt.(query.CreateTable)
-			res, err = server.queryExecutor.CreateTable(conn, stmt)
-		case sql.CreateIndexStatement:
-			stmt := stmt.(query.CreateIndex)
-			res, err = server.exQueryExecutor.CreateIndex(conn, stmt)
-		case sql.AlterDatabaseStatement:
-			stmt := stmt.(query.AlterDatabase)
-			res, err = server.queryExecutor.AlterDatabase(conn, stmt)
-		case sql.AlterTableStatement:
-			stmt := stmt.(query.AlterTable)
-			res, err = server.queryExecutor.AlterTable(conn, stmt)
-		case sql.DropDatabaseStatement:
-			stmt := stmt.(query.DropDatabase)
-			res, err = server.queryExecutor.DropDatabase(conn, stmt)
-		case sql.DropTableStatement:
-			stmt := stmt.(query.DropTable)
-			res, err = server.queryExecutor.DropTable(conn, stmt)
-		case sql.DropIndexStatement:
-			stmt := stmt.(query.DropIndex)
-			res, err = server.exQueryExecutor.DropIndex(conn, stmt)
-		case sql.InsertStatement:
-			stmt := stmt.(query.Insert)
-			res, err = server.queryExecutor.Insert(conn, stmt)
-		case sql.SelectStatement:
-			stmt := stmt.(query.Select)
-			isSystemSelect := func(query.Select) bool {
-				from := stmt.From()
-				if len(from) == 0 {
-					return true
-				}
-				if from.HasSchemaTable(system.SystemSchemaNames...) {
-					return true
-				}
-				return false
-			}
-			if isSystemSelect(stmt) {
-				res, err = server.systemQueryExecutor.SystemSelect(conn, stmt)
-			} else {
-				res, err = server.queryExecutor.Select(conn, stmt)
-			}
-			if !sendRowDescription && 0 < len(res) {
-				if _, ok := res[0].(*protocol.RowDescription); ok {
+		}
+	case sql.RollbackStatement:
+		stmt := stmt.(query.Rollback)
+		if conn.TransactionState() == protocol.TransactionIdleState {
+			return server.responseTransactionWarning(conn, errors.NewWarningNoActiveTransaction(), stmt)
+		}
+		res, err = server.queryExecutor.Rollback(conn, stmt)
+	case sql.CreateDatabaseStatement:
+		stmt := stmt.(query.CreateDatabase)
+		res, err = server.queryExecutor.CreateDatabase(conn, stmt)
+	case sql.CreateTableStatement:
+		stmt := stmt.(query.CreateTable)
+		res, err = server.queryExecutor.CreateTable(conn, stmt)
+	case sql.CreateIndexStatement:
+		stmt := stmt.(query.CreateIndex)
+		res, err = server.exQueryExecutor.CreateIndex(conn, stmt)
+	case sql.AlterDatabaseStatement:
+		stmt := stmt.(query.AlterDatabase)
+		res, err = server.queryExecutor.AlterDatabase(conn, stmt)
+	case sql.AlterTableStatement:
+		stmt := stmt.(query.AlterTable)
+		res, err = server.queryExecutor.AlterTable(conn, stmt)
+	case sql.DropDatabaseStatement:
+		stmt := stmt.(query.DropDatabase)
+		res, err = server.queryExecutor.DropDatabase(conn, stmt)
+	case sql.DropTableStatement:
+		stmt := stmt.(query.DropTable)
+		res, err = server.queryExecutor.DropTable(conn, stmt)
+	case sql.DropIndexStatement:
+		stmt := stmt.(query.DropIndex)
+		res, err = server.exQueryExecutor.DropIndex(conn, stmt)
+	case sql.InsertStatement:
+		stmt := stmt.(query.Insert)
+		res, err = server.queryExecutor.Insert(conn, stmt)
+	case sql.SelectStatement:
+		stmt := stmt.(query.Select)
+		resStream, ok, streamErr := server.selectStream(conn, stmt)
+		if streamErr != nil {
+			return nil, streamErr
+		}
+		if ok {
+			resStream.RowDescription().SetResultFormats(resFmts)
+			return nil, server.responseResultSetStream(conn, resStream, sendRowDescription)
+		}
+		switch {
+		case system.IsPreparedXactsQuery(stmt):
+			res, err = server.selectPreparedXacts(stmt)
+		case isSystemSelect(stmt):
+			res, err = server.systemQueryExecutor.SystemSelect(conn, stmt)
+		default:
+			res, err = server.queryExecutor.Select(conn, stmt)
+		}
+		if 0 < len(res) {
+			// The DataRows share the fields of the RowDescription, so they are encoded in the format codes too.
+			if rowDesc, ok := res[0].(*protocol.RowDescription); ok {
+				rowDesc.SetResultFormats(resFmts)
+				if !sendRowDescription {
 					res = res[1:]
 				}
 			}
-		case sql.UpdateStatement:
-			stmt := stmt.(query.Update)
-			res, err = server.queryExecutor.Update(conn, stmt)
-		case sql.DeleteStatement:
-			stmt := stmt.(query.Delete)
-			res, err = server.queryExecutor.Delete(conn, stmt)
-		case sql.TruncateStatement:
-			stmt := stmt.(query.Truncate)
-			res, err = server.exQueryExecutor.Truncate(conn, stmt)
-		case sql.VacuumStatement:
-			stmt := stmt.(query.Vacuum)
-			res, err = server.exQueryExecutor.Vacuum(conn, stmt)
-		case sql.CopyStatement:
-			stmt := stmt.(query.Copy)
-			res, err = handleCopyQuery(conn, stmt)
 		}
+	case sql.UpdateStatement:
+		stmt := stmt.(query.Update)
+		res, err = server.queryExecutor.Update(conn, stmt)
+	case sql.DeleteStatement:
+		stmt := stmt.(query.Delete)
+		res, err = server.queryExecutor.Delete(conn, stmt)
+	case sql.TruncateStatement:
+		stmt := stmt.(query.Truncate)
+		res, err = server.exQueryExecutor.Truncate(conn, stmt)
+	case sql.VacuumStatement:
+		stmt := stmt.(query.Vacuum)
+		res, err = server.exQueryExecutor.Vacuum(conn, stmt)
+	case sql.CopyStatement:
+		stmt := stmt.(query.Copy)
+		res, err = handleCopyQuery(conn, stmt)
+	}
 
-		if 0 < len(res) {
-			err = conn.ResponseMessages(res)
-			if err != nil {
-				return nil, err
+	return res, err
+}
+
+// transactionCommandOf returns the transaction command of the specified statement.
+func transactionCommandOf(stmt sqlstmt.Statement) protocol.TransactionCommand {
+	switch stmt.StatementType() { // nolint:exhaustive
+	case sql.BeginStatement:
+		return protocol.TransactionBeginCommand
+	case sql.CommitStatement, sql.RollbackStatement:
+		return protocol.TransactionEndCommand
+	}
+	return protocol.TransactionNoneCommand
+}
+
+// isWriteStatement returns true if the specified statement modifies data or schemas.
+func isWriteStatement(stmt sqlstmt.Statement) bool {
+	switch stmt.StatementType() { // nolint:exhaustive
+	case sql.InsertStatement, sql.UpdateStatement, sql.DeleteStatement, sql.TruncateStatement, sql.CopyStatement,
+		sql.CreateDatabaseStatement, sql.CreateTableStatement, sql.CreateIndexStatement,
+		sql.AlterDatabaseStatement, sql.AlterTableStatement, sql.AlterIndexStatement,
+		sql.DropDatabaseStatement, sql.DropTableStatement, sql.DropIndexStatement:
+		return true
+	}
+	return false
+}
+
+// responseTransactionWarning sends the specified warning and returns the CommandComplete of the transaction control statement
+// which is ignored because of the transaction state.
+func (server *server) responseTransactionWarning(conn Conn, warning *errors.SQLError, stmt sqlstmt.Statement) (protocol.Responses, error) {
+	if err := conn.ResponseNotice(warning); err != nil {
+		return nil, err
+	}
+	return protocol.NewCommandCompleteResponsesWith(stmt.String())
+}
+
+// isSystemSelect returns true if the specified SELECT query has no table or refers to the system tables.
+func isSystemSelect(stmt query.Select) bool {
+	from := stmt.From()
+	if len(from) == 0 {
+		return true
+	}
+	if from.HasSchemaTable(system.SystemSchemaNames...) {
+		return true
+	}
+	return system.IsPreparedXactsQuery(stmt)
+}
+
+// selectPreparedXacts returns the responses of the specified SELECT query for the pg_prepared_xacts view
+// from the prepared transactions of the server.
+func (server *server) selectPreparedXacts(stmt query.Select) (protocol.Responses, error) {
+	rs, err := system.NewPreparedXactsResultSetFrom(stmt, server.preparedXacts.PreparedTransactions())
+	if err != nil {
+		return nil, err
+	}
+	return query.NewResponseFromResultSet(rs)
+}
+
+// describeSelect returns the result set schema of the specified SELECT query without executing it.
+// It returns false if the result set schema is not known before the execution.
+func (server *server) describeSelect(conn Conn, stmt query.Select) (resultset.Schema, bool, error) {
+	if isSystemSelect(stmt) {
+		schema, err := system.NewSchemaForSelect(stmt)
+		if err != nil {
+			return nil, false, nil
+		}
+		return schema, true, nil
+	}
+	for _, executor := range []any{server.queryExecutor, server.sqlExecutor} {
+		describeExecutor, ok := executor.(DescribeExecutor)
+		if !ok {
+			continue
+		}
+		schema, err := describeExecutor.DescribeSelect(conn, stmt)
+		if err != nil {
+			return nil, false, err
+		}
+		return schema, schema != nil, nil
+	}
+	return nil, false, nil
+}
+
+// savepointExecutor returns the savepoint executor if the executor handles savepoints.
+func (server *server) savepointExecutor() (SavepointExecutor, bool) {
+	for _, executor := range []any{server.sqlExecutor, server.queryExecutor} {
+		savepointExecutor, ok := executor.(SavepointExecutor)
+		if ok {
+			return savepointExecutor, true
+		}
+	}
+	return nil, false
+}
+
+// twoPhaseCommitExecutor returns the two-phase commit executor if the executor handles two-phase commit.
+func (server *server) twoPhaseCommitExecutor() (TwoPhaseCommitExecutor, bool) {
+	for _, executor := range []any{server.sqlExecutor, server.queryExecutor} {
+		twoPhaseCommitExecutor, ok := executor.(TwoPhaseCommitExecutor)
+		if ok {
+			return twoPhaseCommitExecutor, true
+		}
+	}
+	return nil, false
+}
+
+// tableSchemaLookup returns the table schema lookup function of the executor if the executor provides the table schemas.
+func (server *server) tableSchemaLookup(conn Conn) query.SchemaLookup {
+	for _, executor := range []any{server.sqlExecutor, server.queryExecutor} {
+		schemaExecutor, ok := executor.(SchemaExecutor)
+		if !ok {
+			continue
+		}
+		return func(tableName string) (query.Schema, bool) {
+			schema, err := schemaExecutor.TableSchema(conn, tableName)
+			if err != nil || schema == nil {
+				return nil, false
 			}
+			return schema, true
+		}
+	}
+	return nil
+}
+
+// selectStream returns the result set stream of the specified SELECT query if the executor supports streaming.
+func (server *server) selectStream(conn Conn, stmt query.Select) (*query.ResultSetStream, bool, error) {
+	if system.IsPreparedXactsQuery(stmt) {
+		return nil, false, nil
+	}
+	if isSystemSelect(stmt) {
+		executor, ok := server.systemQueryExecutor.(SystemDMOStreamExecutor)
+		if !ok {
+			return nil, false, nil
+		}
+		resStream, err := executor.SystemSelectStream(conn, stmt)
+		return resStream, true, err
+	}
+	executor, ok := server.queryExecutor.(DMOStreamExecutor)
+	if !ok {
+		return nil, false, nil
+	}
+	resStream, err := executor.SelectStream(conn, stmt)
+	return resStream, true, err
+}
+
+// responseResultSetStream sends the RowDescription, and then encodes and sends each row as the result set produces it,
+// and finally sends the CommandComplete. The buffered connection delivers the rows when the buffer is full,
+// so the backpressure comes from the socket.
+func (server *server) responseResultSetStream(conn Conn, resStream *query.ResultSetStream, sendRowDescription bool) error {
+	defer resStream.Close()
+
+	if sendRowDescription {
+		if err := conn.ResponseMessage(resStream.RowDescription()); err != nil {
+			return err
 		}
+	}
+
+	for resStream.Next() {
+		// Stops sending the rows if the query is canceled by a CancelRequest.
+		if err := conn.Context().Err(); err != nil {
+			return errors.NewErrQueryCanceled(err)
+		}
+		res, err := resStream.Response()
 		if err != nil {
-			return nil, err
+			return err
+		}
+		if err := conn.ResponseMessage(res); err != nil {
+			return err
 		}
 	}
 
-	return nil, nil
+	return nil
 }
@@ -15,32 +15,18 @@
 package postgresql
 
 import (
-	"crypto/rand"
 	"fmt"
-	"math"
-	"math/big"
-	"os"
 
-	"github.com/cybergarage/go-logger/log"
+	"github.com/cybergarage/go-postgresql/postgresql/auth"
 	"github.com/cybergarage/go-postgresql/postgresql/protocol"
 )
 
-// protocolStartupHandler  a new protocol startup executor.
-type protocolStartupHandler struct {
-	processID int32
-	secretKey int32
-}
-
-// newProtocolStartupHandler returns a new protocol startup executor.
-func newProtocolStartupHandler() *protocolStartupHandler {
-	r, err := rand.Int(rand.Reader, big.NewInt(math.MaxInt32))
-	if err != nil {
-		log.Error(err)
-	}
-	return &protocolStartupHandler{
-		processID: int32(os.Getpid()),
-		secretKey: int32(r.Int64()),
+// AuthenticationMethod returns the authentication method for the connection of the start-up message.
+func (server *server) AuthenticationMethod(conn Conn, msg *protocol.Startup) (auth.Method, error) {
+	if server.authMethodPolicy == nil {
+		return server.AuthMethod(), nil
 	}
+	return server.authMethodPolicy.AuthenticationMethod(conn, msg)
 }
 
 // ParameterStatuses returns the parameter statuses.
@@ -57,7 +43,7 @@ func (server *server) ParameterStatuses(Conn) (protocol.Responses, error) {
 	return protocol.NewParameterStatusesWith(m)
 }
 
-// BackendKeyData returns the backend key data.
-func (server *server) BackendKeyData(Conn) (protocol.Response, error) {
-	return protocol.NewBackendKeyDataWith(server.processID, server.secretKey)
+// BackendKeyData returns the backend key data of the connection.
+func (server *server) BackendKeyData(conn Conn) (protocol.Response, error) {
+	return protocol.NewBackendKeyDataWith(conn.ProcessID(), conn.SecretKey())
 }
@@ -0,0 +1,83 @@
+// Copyright (C) 2019 The go-postgresql Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stmt
+
+import (
+	"github.com/cybergarage/go-postgresql/postgresql/protocol"
+)
+
+// PostgreSQL: Documentation: 16: 55.2. Message Flow
+// https://www.postgresql.org/docs/16/protocol-flow.html#PROTOCOL-FLOW-EXT-QUERY
+
+// PortalCursor represents an open result cursor of a portal which is suspended by the row limit of Execute messages.
+type PortalCursor struct {
+	stream  protocol.ResponseStream
+	pending protocol.Response
+}
+
+// NewPortalCursorWith returns a new portal cursor with the specified response stream.
+func NewPortalCursorWith(stream protocol.ResponseStream) *PortalCursor {
+	return &PortalCursor{
+		stream:  stream,
+		pending: nil,
+	}
+}
+
+// peek returns the next response without consuming it, or false if no more responses remain.
+func (cursor *PortalCursor) peek() (protocol.Response, bool, error) {
+	if cursor.pending != nil {
+		return cursor.pending, true, nil
+	}
+	if !cursor.stream.Next() {
+		return nil, false, nil
+	}
+	res, err := cursor.stream.Response()
+	if err != nil {
+		return nil, false, err
+	}
+	cursor.pending = res
+	return res, true, nil
+}
+
+// Fetch returns the next responses which have at most the specified number of data rows.
+// If data rows still remain, the responses end with a PortalSuspended message and it returns true.
+// Zero or a negative number denotes no limit.
+func (cursor *PortalCursor) Fetch(maxRows int) (protocol.Responses, bool, error) {
+	fetched := protocol.NewResponses()
+	nRows := 0
+	for {
+		res, ok, err := cursor.peek()
+		if err != nil {
+			return nil, false, err
+		}
+		if !ok {
+			return fetched, false, nil
+		}
+		if res.Type() == protocol.DataRowMessage {
+			if 0 < maxRows && maxRows <= nRows {
+				return fetched.Append(protocol.NewPortalSuspended()), true, nil
+			}
+			nRows++
+		}
+		fetched = fetched.Append(res)
+		cursor.pending = nil
+	}
+}
+
+// Close closes the response stream of the cursor.
+func (cursor *PortalCursor) Close() error {
+	cursor.pending = nil
+	return cursor.stream.Close()
+}
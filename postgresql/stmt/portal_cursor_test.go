@@ -0,0 +1,82 @@
+// Copyright (C) 2019 The go-postgresql Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stmt
+
+import (
+	"testing"
+
+	"github.com/cybergarage/go-postgresql/postgresql/protocol"
+)
+
+func TestPortalCursor(t *testing.T) {
+	newResponses := func(nRows int) protocol.Responses {
+		res := protocol.NewResponses()
+		for range nRows {
+			res = res.Append(protocol.NewDataRow())
+		}
+		cmpRes, err := protocol.NewSelectCompleteWith(nRows)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res.Append(cmpRes)
+	}
+
+	countTypes := func(res protocol.Responses) (int, int, int) {
+		nRows, nSuspended, nCompleted := 0, 0, 0
+		for _, r := range res {
+			switch r.Type() { // nolint:exhaustive
+			case protocol.DataRowMessage:
+				nRows++
+			case protocol.PortalSuspendedMessage:
+				nSuspended++
+			case protocol.CommandCompleteMessage:
+				nCompleted++
+			}
+		}
+		return nRows, nSuspended, nCompleted
+	}
+
+	tests := []struct {
+		nRows    int
+		maxRows  int
+		expected [][3]int
+	}{
+		{5, 0, [][3]int{{5, 0, 1}}},
+		{5, 2, [][3]int{{2, 1, 0}, {2, 1, 0}, {1, 0, 1}}},
+		{4, 2, [][3]int{{2, 1, 0}, {2, 0, 1}}},
+		{0, 2, [][3]int{{0, 0, 1}}},
+	}
+
+	for _, test := range tests {
+		cursor := NewPortalCursorWith(protocol.NewResponseStreamWith(newResponses(test.nRows)))
+		for n, expected := range test.expected {
+			res, suspended, err := cursor.Fetch(test.maxRows)
+			if err != nil {
+				t.Error(err)
+				break
+			}
+			nRows, nSuspended, nCompleted := countTypes(res)
+			if [3]int{nRows, nSuspended, nCompleted} != expected {
+				t.Errorf("rows %d (max %d) fetch[%d] : %v != %v", test.nRows, test.maxRows, n, [3]int{nRows, nSuspended, nCompleted}, expected)
+			}
+			if suspended != (0 < nSuspended) {
+				t.Errorf("rows %d (max %d) fetch[%d] : suspended %t", test.nRows, test.maxRows, n, suspended)
+			}
+		}
+		if err := cursor.Close(); err != nil {
+			t.Error(err)
+		}
+	}
+}
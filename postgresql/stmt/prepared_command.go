@@ -0,0 +1,369 @@
+// Copyright (C) 2019 The go-postgresql Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stmt
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/cybergarage/go-postgresql/postgresql/errors"
+	"github.com/cybergarage/go-postgresql/postgresql/protocol"
+	"github.com/cybergarage/go-postgresql/postgresql/query"
+	sqlquery "github.com/cybergarage/go-sqlparser/sql/query"
+)
+
+// PostgreSQL: Documentation: 16: PREPARE
+// https://www.postgresql.org/docs/16/sql-prepare.html
+// PostgreSQL: Documentation: 16: EXECUTE
+// https://www.postgresql.org/docs/16/sql-execute.html
+// PostgreSQL: Documentation: 16: DEALLOCATE
+// https://www.postgresql.org/docs/16/sql-deallocate.html
+
+// PreparedCommandType represents a SQL-level prepared statement command type.
+type PreparedCommandType int
+
+const (
+	// PrepareCommand represents a PREPARE command.
+	PrepareCommand PreparedCommandType = iota
+	// ExecuteCommand represents an EXECUTE command.
+	ExecuteCommand
+	// DeallocateCommand represents a DEALLOCATE command.
+	DeallocateCommand
+)
+
+const (
+	prepareKeyword    = "PREPARE"
+	executeKeyword    = "EXECUTE"
+	deallocateKeyword = "DEALLOCATE"
+	asKeyword         = "AS"
+	allKeyword        = "ALL"
+	nullKeyword       = "NULL"
+	trueKeyword       = "TRUE"
+	falseKeyword      = "FALSE"
+)
+
+// PreparedCommand represents a SQL-level PREPARE, EXECUTE, or DEALLOCATE command.
+type PreparedCommand struct {
+	Type PreparedCommandType
+	// Name is the prepared statement name.
+	Name string
+	// DataTypes are the parameter data types of the PREPARE command, and the unknown data types are zero.
+	DataTypes []protocol.ObjectID
+	// Query is the prepared statement query of the PREPARE command.
+	Query string
+	// Params are the parameter values of the EXECUTE command.
+	Params []any
+	// IsAll is true if the DEALLOCATE command deallocates all prepared statements.
+	IsAll bool
+}
+
+// IsPreparedCommand returns true if the specified query starts with a SQL-level prepared statement command.
+func IsPreparedCommand(q string) bool {
+	if IsPreparedTransactionCommand(q) {
+		return false
+	}
+	keyword, _ := nextWord(q)
+	switch strings.ToUpper(keyword) {
+	case prepareKeyword, executeKeyword, deallocateKeyword:
+		return true
+	}
+	return false
+}
+
+// HasPreparedCommand returns true if the specified query has a SQL-level prepared statement command.
+func HasPreparedCommand(q string) bool {
+	for _, s := range SplitQueries(q) {
+		if IsPreparedCommand(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasUnparsedCommand returns true if the specified query has a command which the SQL parser does not support,
+// such as the SQL-level prepared statement commands, the savepoint commands, the transaction mode commands,
+// and the two-phase commit commands.
+func HasUnparsedCommand(q string) bool {
+	return HasPreparedCommand(q) || HasSavepointCommand(q) || HasTransactionModeCommand(q) || HasPreparedTransactionCommand(q)
+}
+
+// NewPreparedCommandFrom returns a SQL-level prepared statement command of the specified query.
+func NewPreparedCommandFrom(q string) (*PreparedCommand, error) {
+	keyword, rest := nextWord(q)
+	switch strings.ToUpper(keyword) {
+	case prepareKeyword:
+		return newPrepareCommandFrom(q, rest)
+	case executeKeyword:
+		return newExecuteCommandFrom(q, rest)
+	case deallocateKeyword:
+		return newDeallocateCommandFrom(q, rest)
+	}
+	return nil, errors.NewErrSyntax(errors.NewErrNotSupported(q))
+}
+
+// newPrepareCommandFrom returns a PREPARE name [ ( data_type [, ...] ) ] AS statement command.
+func newPrepareCommandFrom(q string, rest string) (*PreparedCommand, error) {
+	name, rest := nextName(rest)
+	if len(name) == 0 {
+		return nil, errors.NewErrSyntax(errors.NewErrInvalid(q))
+	}
+
+	dataTypes := []protocol.ObjectID{}
+	rest = strings.TrimSpace(rest)
+	if strings.HasPrefix(rest, "(") {
+		args, after, ok := cutParentheses(rest)
+		if !ok {
+			return nil, errors.NewErrSyntax(errors.NewErrInvalid(q))
+		}
+		for _, arg := range args {
+			dataTypes = append(dataTypes, newObjectIDFromTypeName(arg))
+		}
+		rest = after
+	}
+
+	keyword, stmt := nextWord(rest)
+	stmt = strings.TrimSpace(stmt)
+	if !strings.EqualFold(keyword, asKeyword) || len(stmt) == 0 {
+		return nil, errors.NewErrSyntax(errors.NewErrInvalid(q))
+	}
+
+	return &PreparedCommand{
+		Type:      PrepareCommand,
+		Name:      name,
+		DataTypes: dataTypes,
+		Query:     stmt,
+		Params:    nil,
+		IsAll:     false,
+	}, nil
+}
+
+// newExecuteCommandFrom returns an EXECUTE name [ ( parameter [, ...] ) ] command.
+func newExecuteCommandFrom(q string, rest string) (*PreparedCommand, error) {
+	name, rest := nextName(rest)
+	if len(name) == 0 {
+		return nil, errors.NewErrSyntax(errors.NewErrInvalid(q))
+	}
+
+	params := []any{}
+	rest = strings.TrimSpace(rest)
+	if strings.HasPrefix(rest, "(") {
+		args, after, ok := cutParentheses(rest)
+		if !ok || len(strings.TrimSpace(after)) != 0 {
+			return nil, errors.NewErrSyntax(errors.NewErrInvalid(q))
+		}
+		for _, arg := range args {
+			param, err := newParamFromLiteral(arg)
+			if err != nil {
+				return nil, err
+			}
+			params = append(params, param)
+		}
+	} else if len(rest) != 0 {
+		return nil, errors.NewErrSyntax(errors.NewErrInvalid(q))
+	}
+
+	return &PreparedCommand{
+		Type:      ExecuteCommand,
+		Name:      name,
+		DataTypes: nil,
+		Query:     "",
+		Params:    params,
+		IsAll:     false,
+	}, nil
+}
+
+// newDeallocateCommandFrom returns a DEALLOCATE [ PREPARE ] { name | ALL } command.
+func newDeallocateCommandFrom(q string, rest string) (*PreparedCommand, error) {
+	if keyword, after := nextWord(rest); strings.EqualFold(keyword, prepareKeyword) {
+		rest = after
+	}
+	name, rest := nextName(rest)
+	if len(name) == 0 || len(strings.TrimSpace(rest)) != 0 {
+		return nil, errors.NewErrSyntax(errors.NewErrInvalid(q))
+	}
+	isAll := strings.EqualFold(name, allKeyword)
+	if isAll {
+		name = ""
+	}
+	return &PreparedCommand{
+		Type:      DeallocateCommand,
+		Name:      name,
+		DataTypes: nil,
+		Query:     "",
+		Params:    nil,
+		IsAll:     isAll,
+	}, nil
+}
+
+// CommandTag returns the tag of the CommandComplete message of the command.
+func (cmd *PreparedCommand) CommandTag() string {
+	switch cmd.Type {
+	case PrepareCommand:
+		return prepareKeyword
+	case DeallocateCommand:
+		if cmd.IsAll {
+			return deallocateKeyword + " " + allKeyword
+		}
+		return deallocateKeyword
+	case ExecuteCommand:
+		return executeKeyword
+	}
+	return ""
+}
+
+// NewParse returns a Parse message of the PREPARE command.
+func (cmd *PreparedCommand) NewParse() *protocol.Parse {
+	return protocol.NewParseWith(cmd.Name, cmd.Query, cmd.DataTypes...)
+}
+
+// NewBind returns a Bind message of the EXECUTE command for the unnamed portal.
+func (cmd *PreparedCommand) NewBind() *protocol.Bind {
+	params := make(protocol.BindParams, len(cmd.Params))
+	for n, v := range cmd.Params {
+		params[n] = &protocol.BindParam{
+			FormatCode: protocol.TextFormat,
+			ObjectID:   0,
+			Value:      v,
+		}
+	}
+	return protocol.NewBindWith("", cmd.Name, params)
+}
+
+// SplitQueries splits the specified query into the statements by the semicolons outside of the quoted strings and identifiers.
+func SplitQueries(q string) []string {
+	queries := []string{}
+	appendQuery := func(s string) {
+		s = strings.TrimSpace(s)
+		if len(s) != 0 {
+			queries = append(queries, s)
+		}
+	}
+	var quote byte
+	start := 0
+	for n := 0; n < len(q); n++ {
+		c := q[n]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == ';':
+			appendQuery(q[start:n])
+			start = n + 1
+		}
+	}
+	appendQuery(q[start:])
+	return queries
+}
+
+// nextWord returns the first word and the rest of the specified string.
+func nextWord(s string) (string, string) {
+	s = strings.TrimSpace(s)
+	end := strings.IndexFunc(s, func(r rune) bool {
+		return r == ' ' || r == '\t' || r == '\n' || r == '\r' || r == '(' || r == ';'
+	})
+	if end < 0 {
+		return s, ""
+	}
+	return s[:end], s[end:]
+}
+
+// nextName returns the first name which may be double-quoted and the rest of the specified string.
+// The unquoted names are folded to lower case.
+func nextName(s string) (string, string) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, `"`) {
+		end := strings.Index(s[1:], `"`)
+		if end < 0 {
+			return "", s
+		}
+		return s[1 : end+1], s[end+2:]
+	}
+	name, rest := nextWord(s)
+	return strings.ToLower(name), rest
+}
+
+// cutParentheses returns the comma separated elements in the leading parentheses and the rest of the specified string.
+func cutParentheses(s string) ([]string, string, bool) {
+	elems := []string{}
+	var quote byte
+	depth := 0
+	start := 1
+	for n := 0; n < len(s); n++ {
+		c := s[n]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+			if depth == 0 {
+				if elem := strings.TrimSpace(s[start:n]); len(elem) != 0 || 0 < len(elems) {
+					elems = append(elems, elem)
+				}
+				return elems, s[n+1:], true
+			}
+		case c == ',' && depth == 1:
+			elems = append(elems, strings.TrimSpace(s[start:n]))
+			start = n + 1
+		}
+	}
+	return nil, s, false
+}
+
+// newObjectIDFromTypeName returns the object ID of the specified data type name, or zero for the unknown data types.
+func newObjectIDFromTypeName(name string) protocol.ObjectID {
+	// Removes the type modifiers such as VARCHAR(255).
+	if idx := strings.Index(name, "("); 0 < idx {
+		name = name[:idx]
+	}
+	dt, err := sqlquery.NewDataTypeFromString(strings.TrimSpace(name))
+	if err != nil {
+		return 0
+	}
+	oid, err := query.NewObjectIDFrom(dt)
+	if err != nil {
+		return 0
+	}
+	return oid
+}
+
+// newParamFromLiteral returns the parameter value of the specified SQL literal.
+func newParamFromLiteral(literal string) (any, error) {
+	switch {
+	case strings.EqualFold(literal, nullKeyword):
+		return nil, nil
+	case strings.EqualFold(literal, trueKeyword):
+		return true, nil
+	case strings.EqualFold(literal, falseKeyword):
+		return false, nil
+	case 2 <= len(literal) && strings.HasPrefix(literal, "'") && strings.HasSuffix(literal, "'"):
+		return strings.ReplaceAll(literal[1:len(literal)-1], "''", "'"), nil
+	}
+	if v, err := strconv.ParseInt(literal, 10, 64); err == nil {
+		return v, nil
+	}
+	if v, err := strconv.ParseFloat(literal, 64); err == nil {
+		return v, nil
+	}
+	return nil, errors.NewErrSyntax(errors.NewErrNotSupported(literal))
+}
@@ -0,0 +1,71 @@
+// Copyright (C) 2019 The go-postgresql Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stmt
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/cybergarage/go-postgresql/postgresql/system"
+)
+
+func TestPreparedCommand(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{"PREPARE ins AS INSERT INTO t (k) VALUES ($1)", "0 ins [] INSERT INTO t (k) VALUES ($1) [] false"},
+		{"prepare Ins (int, text) as SELECT * FROM t WHERE k = $1 AND v = $2", fmt.Sprintf("0 ins [%d %d] SELECT * FROM t WHERE k = $1 AND v = $2 [] false", system.Int4, system.Text)},
+		{`PREPARE "Ins" AS DELETE FROM t`, "0 Ins [] DELETE FROM t [] false"},
+		{"EXECUTE ins(1, 'a''b', NULL, 1.5, true)", "1 ins []  [1 a'b <nil> 1.5 true] false"},
+		{"EXECUTE ins", "1 ins []  [] false"},
+		{"DEALLOCATE ins", "2 ins []  [] false"},
+		{"DEALLOCATE PREPARE ins", "2 ins []  [] false"},
+		{"DEALLOCATE ALL", "2  []  [] true"},
+	}
+
+	for _, test := range tests {
+		if !IsPreparedCommand(test.query) {
+			t.Errorf("%s is not a prepared command", test.query)
+			continue
+		}
+		cmd, err := NewPreparedCommandFrom(test.query)
+		if err != nil {
+			t.Errorf("%s: %s", test.query, err)
+			continue
+		}
+		actual := fmt.Sprintf("%d %s %v %s %v %v", cmd.Type, cmd.Name, cmd.DataTypes, cmd.Query, cmd.Params, cmd.IsAll)
+		if actual != test.expected {
+			t.Errorf("%s: %s != %s", test.query, actual, test.expected)
+		}
+	}
+
+	for _, query := range []string{"PREPARE ins", "PREPARE ins INSERT INTO t (k) VALUES (1)", "EXECUTE ins(1", "EXECUTE ins(now())", "DEALLOCATE"} {
+		if _, err := NewPreparedCommandFrom(query); err == nil {
+			t.Errorf("%s: Expected a syntax error", query)
+		}
+	}
+}
+
+func TestSplitQueries(t *testing.T) {
+	queries := SplitQueries("PREPARE p AS SELECT * FROM t WHERE v = ';'; EXECUTE p; ;")
+	expected := "[PREPARE p AS SELECT * FROM t WHERE v = ';' EXECUTE p]"
+	if fmt.Sprintf("%v", queries) != expected {
+		t.Errorf("%v != %s", queries, expected)
+	}
+	if HasPreparedCommand("SELECT 'EXECUTE p'") {
+		t.Errorf("Expected no prepared command")
+	}
+}
@@ -62,6 +62,15 @@ func (mgr *PreparedManager) RemovePreparedStatement(conn Conn, name string) erro
 	return preState.RemovePreparedStatement(name)
 }
 
+// RemoveAllPreparedStatements removes all prepared statements of the connection.
+func (mgr *PreparedManager) RemoveAllPreparedStatements(conn Conn) {
+	preState, ok := mgr.stateMap[conn.UUID()]
+	if !ok {
+		return
+	}
+	preState.RemoveAllPreparedStatements()
+}
+
 // PreparedPortal returns a prepared query statement.
 func (mgr *PreparedManager) PreparedPortal(conn Conn, name string) (*PreparedPortal, error) {
 	prePortal, ok := mgr.potalMap[conn.UUID()]
@@ -24,7 +24,10 @@ import (
 type PreparedStatement struct {
 	*protocol.Parse
 
+	// ParsedStatement is the first statement of the prepared statement, or nil if the query is parsed when it is executed.
 	ParsedStatement *query.Statement
+	// ParsedStatements are all statements of the prepared statement.
+	ParsedStatements []*query.Statement
 }
 
 // Name returns a prepared statement name.
@@ -51,17 +54,24 @@ func (stmtMap PreparedStatementMap) PreparedStatement(name string) (*PreparedSta
 
 // SetPreparedStatement sets a prepared statement.
 func (stmtMap PreparedStatementMap) SetPreparedStatement(msg *protocol.Parse) error {
-	parser := query.NewParser()
-	stmts, err := parser.ParseString(msg.Query)
-	if err != nil {
-		return err
+	// The commands unsupported by the SQL parser are parsed when they are executed.
+	stmts := []*query.Statement{}
+	if !HasUnparsedCommand(msg.Query) {
+		parser := query.NewParser()
+		var err error
+		stmts, err = parser.ParseString(msg.Query)
+		if err != nil {
+			return err
+		}
 	}
-	if 1 < len(stmts) {
-		return errors.NewErrMultiplePreparedStatementNotSupported(msg.Query)
+	var parsedStmt *query.Statement
+	if 0 < len(stmts) {
+		parsedStmt = stmts[0]
 	}
 	stmt := &PreparedStatement{
-		Parse:           msg,
-		ParsedStatement: stmts[0],
+		Parse:            msg,
+		ParsedStatement:  parsedStmt,
+		ParsedStatements: stmts,
 	}
 	stmtMap[msg.Name] = stmt
 	return nil
@@ -77,11 +87,50 @@ func (stmtMap PreparedStatementMap) RemovePreparedStatement(name string) error {
 	return nil
 }
 
+// RemoveAllPreparedStatements removes all prepared statements.
+func (stmtMap PreparedStatementMap) RemoveAllPreparedStatements() {
+	for name := range stmtMap {
+		delete(stmtMap, name)
+	}
+}
+
 // PreparedPortal represents a prepared query statement.
-type PreparedPortal = protocol.Query
+type PreparedPortal struct {
+	*protocol.Query
+	cursor *PortalCursor
+}
+
+// NewPreparedPortalWith returns a new prepared portal with the specified query.
+func NewPreparedPortalWith(q *protocol.Query) *PreparedPortal {
+	return &PreparedPortal{
+		Query:  q,
+		cursor: nil,
+	}
+}
+
+// OpenCursor opens the result cursor of the portal with the specified response stream.
+func (portal *PreparedPortal) OpenCursor(stream protocol.ResponseStream) *PortalCursor {
+	portal.CloseCursor()
+	portal.cursor = NewPortalCursorWith(stream)
+	return portal.cursor
+}
+
+// Cursor returns the open result cursor of the portal.
+func (portal *PreparedPortal) Cursor() (*PortalCursor, bool) {
+	return portal.cursor, portal.cursor != nil
+}
+
+// CloseCursor closes the result cursor of the portal.
+func (portal *PreparedPortal) CloseCursor() {
+	if portal.cursor == nil {
+		return
+	}
+	_ = portal.cursor.Close()
+	portal.cursor = nil
+}
 
 // PreparedPortalMap represents a prepared query statement map.
-type PreparedPortalMap map[string]PreparedPortal
+type PreparedPortalMap map[string]*PreparedPortal
 
 // NewPreparedPortalMap returns a new prepared query statement map.
 func NewPreparedPortalMap() PreparedPortalMap {
@@ -94,21 +143,25 @@ func (portalMap PreparedPortalMap) PreparedPortal(name string) (*PreparedPortal,
 	if !oK {
 		return nil, errors.NewErrPreparedPortalNotExist(name)
 	}
-	return &q, nil
+	return q, nil
 }
 
 // SetPreparedPortal sets a prepared query statement.
 func (portalMap PreparedPortalMap) SetPreparedPortal(name string, query *PreparedPortal) error {
-	portalMap[name] = *query
+	if portal, ok := portalMap[name]; ok {
+		portal.CloseCursor()
+	}
+	portalMap[name] = query
 	return nil
 }
 
 // RemovePreparedPortal removes a prepared query statement.
 func (portalMap PreparedPortalMap) RemovePreparedPortal(name string) error {
-	_, oK := portalMap[name]
+	portal, oK := portalMap[name]
 	if !oK {
 		return errors.NewErrPreparedPortalNotExist(name)
 	}
+	portal.CloseCursor()
 	delete(portalMap, name)
 	return nil
 }
@@ -0,0 +1,99 @@
+// Copyright (C) 2019 The go-postgresql Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stmt
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cybergarage/go-postgresql/postgresql/errors"
+	"github.com/cybergarage/go-postgresql/postgresql/system"
+)
+
+// PreparedTransaction represents a transaction which is prepared for two-phase commit.
+type PreparedTransaction = system.PreparedXact
+
+// PreparedTransactionManager represents a server-wide manager of the prepared transactions.
+// The prepared transactions are not bound to any connection, and they survive the connection close
+// until they are committed or rolled back by COMMIT PREPARED or ROLLBACK PREPARED.
+type PreparedTransactionManager struct {
+	sync.Mutex
+	xacts   map[string]*PreparedTransaction
+	lastXID int
+}
+
+// NewPreparedTransactionManager returns a new prepared transaction manager.
+func NewPreparedTransactionManager() *PreparedTransactionManager {
+	return &PreparedTransactionManager{
+		Mutex:   sync.Mutex{},
+		xacts:   make(map[string]*PreparedTransaction),
+		lastXID: 0,
+	}
+}
+
+// ReservePreparedTransaction reserves the specified global transaction identifier for the connection,
+// and returns the prepared transaction which is registered until it is removed.
+func (mgr *PreparedTransactionManager) ReservePreparedTransaction(conn Conn, gid string) (*PreparedTransaction, error) {
+	mgr.Lock()
+	defer mgr.Unlock()
+	if _, ok := mgr.xacts[gid]; ok {
+		return nil, errors.NewErrPreparedTransactionExist(gid)
+	}
+	mgr.lastXID++
+	xact := &PreparedTransaction{
+		Transaction: mgr.lastXID,
+		GID:         gid,
+		Prepared:    time.Now(),
+		Owner:       conn.User(),
+		Database:    conn.Database(),
+	}
+	mgr.xacts[gid] = xact
+	return xact, nil
+}
+
+// TakePreparedTransaction removes the prepared transaction of the specified global transaction identifier and returns it,
+// so only one connection can finish the prepared transaction.
+func (mgr *PreparedTransactionManager) TakePreparedTransaction(gid string) (*PreparedTransaction, error) {
+	mgr.Lock()
+	defer mgr.Unlock()
+	xact, ok := mgr.xacts[gid]
+	if !ok {
+		return nil, errors.NewErrPreparedTransactionNotExist(gid)
+	}
+	delete(mgr.xacts, gid)
+	return xact, nil
+}
+
+// RestorePreparedTransaction registers the specified prepared transaction again.
+func (mgr *PreparedTransactionManager) RestorePreparedTransaction(xact *PreparedTransaction) {
+	mgr.Lock()
+	defer mgr.Unlock()
+	mgr.xacts[xact.GID] = xact
+}
+
+// PreparedTransactions returns all prepared transactions in the prepared order.
+func (mgr *PreparedTransactionManager) PreparedTransactions() []*PreparedTransaction {
+	mgr.Lock()
+	defer mgr.Unlock()
+	xacts := make([]*PreparedTransaction, 0, len(mgr.xacts))
+	for _, xact := range mgr.xacts {
+		xacts = append(xacts, xact)
+	}
+	sort.Slice(xacts, func(i, j int) bool {
+		return xacts[i].Transaction < xacts[j].Transaction
+	})
+	return xacts
+}
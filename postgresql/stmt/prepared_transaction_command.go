@@ -0,0 +1,125 @@
+// Copyright (C) 2019 The go-postgresql Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stmt
+
+import (
+	"strings"
+
+	"github.com/cybergarage/go-postgresql/postgresql/errors"
+)
+
+// PostgreSQL: Documentation: 16: PREPARE TRANSACTION
+// https://www.postgresql.org/docs/16/sql-prepare-transaction.html
+// PostgreSQL: Documentation: 16: COMMIT PREPARED
+// https://www.postgresql.org/docs/16/sql-commit-prepared.html
+// PostgreSQL: Documentation: 16: ROLLBACK PREPARED
+// https://www.postgresql.org/docs/16/sql-rollback-prepared.html
+
+// PreparedTransactionCommandType represents a two-phase commit command type.
+type PreparedTransactionCommandType int
+
+const (
+	// PrepareTransactionCommand represents a PREPARE TRANSACTION command.
+	PrepareTransactionCommand PreparedTransactionCommandType = iota
+	// CommitPreparedCommand represents a COMMIT PREPARED command.
+	CommitPreparedCommand
+	// RollbackPreparedCommand represents a ROLLBACK PREPARED command.
+	RollbackPreparedCommand
+)
+
+const (
+	commitKeyword   = "COMMIT"
+	preparedKeyword = "PREPARED"
+)
+
+// PreparedTransactionCommand represents a PREPARE TRANSACTION, COMMIT PREPARED, or ROLLBACK PREPARED command.
+type PreparedTransactionCommand struct {
+	Type PreparedTransactionCommandType
+	// GID is the global transaction identifier.
+	GID string
+}
+
+// IsPreparedTransactionCommand returns true if the specified query is a two-phase commit command.
+func IsPreparedTransactionCommand(q string) bool {
+	keyword, rest := nextWord(q)
+	next, _ := nextWord(rest)
+	switch strings.ToUpper(keyword) {
+	case prepareKeyword:
+		return strings.EqualFold(next, transactionKeyword)
+	case commitKeyword, rollbackKeyword:
+		return strings.EqualFold(next, preparedKeyword)
+	}
+	return false
+}
+
+// HasPreparedTransactionCommand returns true if the specified query has a two-phase commit command.
+func HasPreparedTransactionCommand(q string) bool {
+	for _, s := range SplitQueries(q) {
+		if IsPreparedTransactionCommand(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// NewPreparedTransactionCommandFrom returns a two-phase commit command of the specified query.
+func NewPreparedTransactionCommandFrom(q string) (*PreparedTransactionCommand, error) {
+	if !IsPreparedTransactionCommand(q) {
+		return nil, errors.NewErrSyntax(errors.NewErrNotSupported(q))
+	}
+
+	keyword, rest := nextWord(q)
+	_, rest = nextWord(rest)
+
+	var cmdType PreparedTransactionCommandType
+	switch strings.ToUpper(keyword) {
+	case prepareKeyword:
+		cmdType = PrepareTransactionCommand
+	case commitKeyword:
+		cmdType = CommitPreparedCommand
+	case rollbackKeyword:
+		cmdType = RollbackPreparedCommand
+	}
+
+	// The transaction identifier is a string literal.
+	gid := strings.TrimSpace(rest)
+	if len(gid) < 2 || !strings.HasPrefix(gid, "'") || !strings.HasSuffix(gid, "'") {
+		return nil, errors.NewErrSyntax(errors.NewErrInvalid(q))
+	}
+	gid = strings.ReplaceAll(gid[1:len(gid)-1], "''", "'")
+
+	return &PreparedTransactionCommand{
+		Type: cmdType,
+		GID:  gid,
+	}, nil
+}
+
+// CommandTag returns the tag of the CommandComplete message of the command.
+func (cmd *PreparedTransactionCommand) CommandTag() string {
+	return cmd.String()
+}
+
+// String returns the statement name of the command.
+func (cmd *PreparedTransactionCommand) String() string {
+	switch cmd.Type {
+	case PrepareTransactionCommand:
+		return prepareKeyword + " " + transactionKeyword
+	case CommitPreparedCommand:
+		return commitKeyword + " " + preparedKeyword
+	case RollbackPreparedCommand:
+		return rollbackKeyword + " " + preparedKeyword
+	}
+	return ""
+}
@@ -0,0 +1,63 @@
+// Copyright (C) 2019 The go-postgresql Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stmt
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestPreparedTransactionCommand(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{"PREPARE TRANSACTION 'gx1'", "0 gx1 PREPARE TRANSACTION"},
+		{"prepare transaction 'it''s'", "0 it's PREPARE TRANSACTION"},
+		{"COMMIT PREPARED 'gx1'", "1 gx1 COMMIT PREPARED"},
+		{"ROLLBACK PREPARED 'gx1'", "2 gx1 ROLLBACK PREPARED"},
+	}
+
+	for _, test := range tests {
+		if !IsPreparedTransactionCommand(test.query) {
+			t.Errorf("%s is not a prepared transaction command", test.query)
+			continue
+		}
+		if IsPreparedCommand(test.query) {
+			t.Errorf("%s is a prepared statement command", test.query)
+		}
+		cmd, err := NewPreparedTransactionCommandFrom(test.query)
+		if err != nil {
+			t.Errorf("%s: %s", test.query, err)
+			continue
+		}
+		actual := fmt.Sprintf("%d %s %s", cmd.Type, cmd.GID, cmd.CommandTag())
+		if actual != test.expected {
+			t.Errorf("%s: %s != %s", test.query, actual, test.expected)
+		}
+	}
+
+	for _, query := range []string{"COMMIT", "ROLLBACK TO SAVEPOINT sp1", "PREPARE ins AS SELECT 1"} {
+		if IsPreparedTransactionCommand(query) {
+			t.Errorf("%s is a prepared transaction command", query)
+		}
+	}
+
+	for _, query := range []string{"PREPARE TRANSACTION", "COMMIT PREPARED gx1", "ROLLBACK PREPARED 'gx1"} {
+		if _, err := NewPreparedTransactionCommandFrom(query); err == nil {
+			t.Errorf("%s: Expected a syntax error", query)
+		}
+	}
+}
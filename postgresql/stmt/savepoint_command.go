@@ -0,0 +1,161 @@
+// Copyright (C) 2019 The go-postgresql Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stmt
+
+import (
+	"strings"
+
+	"github.com/cybergarage/go-postgresql/postgresql/errors"
+	"github.com/cybergarage/go-postgresql/postgresql/protocol"
+)
+
+// PostgreSQL: Documentation: 16: SAVEPOINT
+// https://www.postgresql.org/docs/16/sql-savepoint.html
+// PostgreSQL: Documentation: 16: RELEASE SAVEPOINT
+// https://www.postgresql.org/docs/16/sql-release-savepoint.html
+// PostgreSQL: Documentation: 16: ROLLBACK TO SAVEPOINT
+// https://www.postgresql.org/docs/16/sql-rollback-to.html
+
+// SavepointCommandType represents a savepoint command type.
+type SavepointCommandType int
+
+const (
+	// DefineSavepointCommand represents a SAVEPOINT command.
+	DefineSavepointCommand SavepointCommandType = iota
+	// ReleaseSavepointCommand represents a RELEASE SAVEPOINT command.
+	ReleaseSavepointCommand
+	// RollbackToSavepointCommand represents a ROLLBACK TO SAVEPOINT command.
+	RollbackToSavepointCommand
+)
+
+const (
+	savepointKeyword   = "SAVEPOINT"
+	releaseKeyword     = "RELEASE"
+	rollbackKeyword    = "ROLLBACK"
+	abortKeyword       = "ABORT"
+	toKeyword          = "TO"
+	workKeyword        = "WORK"
+	transactionKeyword = "TRANSACTION"
+)
+
+// SavepointCommand represents a SAVEPOINT, RELEASE SAVEPOINT, or ROLLBACK TO SAVEPOINT command.
+type SavepointCommand struct {
+	Type SavepointCommandType
+	// Name is the savepoint name.
+	Name string
+}
+
+// IsSavepointCommand returns true if the specified query is a savepoint command.
+func IsSavepointCommand(q string) bool {
+	keyword, rest := nextWord(q)
+	switch strings.ToUpper(keyword) {
+	case savepointKeyword, releaseKeyword:
+		return true
+	case rollbackKeyword, abortKeyword:
+		// ROLLBACK [ WORK | TRANSACTION ] TO [ SAVEPOINT ] name
+		keyword, rest = nextWord(rest)
+		if strings.EqualFold(keyword, workKeyword) || strings.EqualFold(keyword, transactionKeyword) {
+			keyword, _ = nextWord(rest)
+		}
+		return strings.EqualFold(keyword, toKeyword)
+	}
+	return false
+}
+
+// HasSavepointCommand returns true if the specified query has a savepoint command.
+func HasSavepointCommand(q string) bool {
+	for _, s := range SplitQueries(q) {
+		if IsSavepointCommand(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// NewSavepointCommandFrom returns a savepoint command of the specified query.
+func NewSavepointCommandFrom(q string) (*SavepointCommand, error) {
+	keyword, rest := nextWord(q)
+	var cmdType SavepointCommandType
+	switch strings.ToUpper(keyword) {
+	case savepointKeyword:
+		cmdType = DefineSavepointCommand
+	case releaseKeyword:
+		// RELEASE [ SAVEPOINT ] name
+		cmdType = ReleaseSavepointCommand
+		if keyword, after := nextWord(rest); strings.EqualFold(keyword, savepointKeyword) {
+			rest = after
+		}
+	case rollbackKeyword, abortKeyword:
+		// ROLLBACK [ WORK | TRANSACTION ] TO [ SAVEPOINT ] name
+		cmdType = RollbackToSavepointCommand
+		keyword, after := nextWord(rest)
+		if strings.EqualFold(keyword, workKeyword) || strings.EqualFold(keyword, transactionKeyword) {
+			keyword, after = nextWord(after)
+		}
+		if !strings.EqualFold(keyword, toKeyword) {
+			return nil, errors.NewErrSyntax(errors.NewErrInvalid(q))
+		}
+		rest = after
+		if keyword, after := nextWord(rest); strings.EqualFold(keyword, savepointKeyword) {
+			rest = after
+		}
+	default:
+		return nil, errors.NewErrSyntax(errors.NewErrNotSupported(q))
+	}
+
+	name, rest := nextName(rest)
+	if len(name) == 0 || len(strings.TrimSpace(rest)) != 0 {
+		return nil, errors.NewErrSyntax(errors.NewErrInvalid(q))
+	}
+
+	return &SavepointCommand{
+		Type: cmdType,
+		Name: name,
+	}, nil
+}
+
+// TransactionCommand returns the transaction command of the command for the transaction state.
+func (cmd *SavepointCommand) TransactionCommand() protocol.TransactionCommand {
+	if cmd.Type == RollbackToSavepointCommand {
+		return protocol.TransactionRollbackToCommand
+	}
+	return protocol.TransactionNoneCommand
+}
+
+// CommandTag returns the tag of the CommandComplete message of the command.
+func (cmd *SavepointCommand) CommandTag() string {
+	switch cmd.Type {
+	case DefineSavepointCommand:
+		return savepointKeyword
+	case ReleaseSavepointCommand:
+		return releaseKeyword
+	case RollbackToSavepointCommand:
+		return rollbackKeyword
+	}
+	return ""
+}
+
+// String returns the statement name of the command.
+func (cmd *SavepointCommand) String() string {
+	switch cmd.Type {
+	case DefineSavepointCommand:
+		return savepointKeyword
+	case ReleaseSavepointCommand:
+		return releaseKeyword + " " + savepointKeyword
+	case RollbackToSavepointCommand:
+		return rollbackKeyword + " " + toKeyword + " " + savepointKeyword
+	}
+	return ""
+}
@@ -0,0 +1,63 @@
+// Copyright (C) 2019 The go-postgresql Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stmt
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestSavepointCommand(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{"SAVEPOINT sp1", "0 sp1 SAVEPOINT"},
+		{`savepoint "Sp1"`, "0 Sp1 SAVEPOINT"},
+		{"RELEASE SAVEPOINT sp1", "1 sp1 RELEASE"},
+		{"RELEASE Sp1", "1 sp1 RELEASE"},
+		{"ROLLBACK TO SAVEPOINT sp1", "2 sp1 ROLLBACK"},
+		{"ROLLBACK WORK TO sp1", "2 sp1 ROLLBACK"},
+		{"ABORT TRANSACTION TO SAVEPOINT sp1", "2 sp1 ROLLBACK"},
+	}
+
+	for _, test := range tests {
+		if !IsSavepointCommand(test.query) {
+			t.Errorf("%s is not a savepoint command", test.query)
+			continue
+		}
+		cmd, err := NewSavepointCommandFrom(test.query)
+		if err != nil {
+			t.Errorf("%s: %s", test.query, err)
+			continue
+		}
+		actual := fmt.Sprintf("%d %s %s", cmd.Type, cmd.Name, cmd.CommandTag())
+		if actual != test.expected {
+			t.Errorf("%s: %s != %s", test.query, actual, test.expected)
+		}
+	}
+
+	for _, query := range []string{"ROLLBACK", "ROLLBACK WORK", "SELECT 'SAVEPOINT sp1'"} {
+		if IsSavepointCommand(query) {
+			t.Errorf("%s is a savepoint command", query)
+		}
+	}
+
+	for _, query := range []string{"SAVEPOINT", "RELEASE SAVEPOINT", "ROLLBACK TO", "SAVEPOINT sp1 sp2"} {
+		if _, err := NewSavepointCommandFrom(query); err == nil {
+			t.Errorf("%s: Expected a syntax error", query)
+		}
+	}
+}
@@ -0,0 +1,274 @@
+// Copyright (C) 2019 The go-postgresql Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stmt
+
+import (
+	"strings"
+
+	"github.com/cybergarage/go-postgresql/postgresql/errors"
+	"github.com/cybergarage/go-postgresql/postgresql/protocol"
+)
+
+// PostgreSQL: Documentation: 16: BEGIN
+// https://www.postgresql.org/docs/16/sql-begin.html
+// PostgreSQL: Documentation: 16: START TRANSACTION
+// https://www.postgresql.org/docs/16/sql-start-transaction.html
+// PostgreSQL: Documentation: 16: SET TRANSACTION
+// https://www.postgresql.org/docs/16/sql-set-transaction.html
+
+// TransactionModeCommandType represents a transaction mode command type.
+type TransactionModeCommandType int
+
+const (
+	// BeginTransactionCommand represents a BEGIN or START TRANSACTION command with transaction modes.
+	BeginTransactionCommand TransactionModeCommandType = iota
+	// SetTransactionCommand represents a SET TRANSACTION command.
+	SetTransactionCommand
+)
+
+const (
+	beginKeyword        = "BEGIN"
+	startKeyword        = "START"
+	endKeyword          = "END"
+	setKeyword          = "SET"
+	isolationKeyword    = "ISOLATION"
+	levelKeyword        = "LEVEL"
+	readKeyword         = "READ"
+	writeKeyword        = "WRITE"
+	onlyKeyword         = "ONLY"
+	committedKeyword    = "COMMITTED"
+	uncommittedKeyword  = "UNCOMMITTED"
+	repeatableKeyword   = "REPEATABLE"
+	serializableKeyword = "SERIALIZABLE"
+	deferrableKeyword   = "DEFERRABLE"
+	notKeyword          = "NOT"
+)
+
+// transactionMode represents a transaction mode which updates the transaction options.
+type transactionMode func(*protocol.TransactionOptions)
+
+// TransactionModeCommand represents a BEGIN, START TRANSACTION, or SET TRANSACTION command with transaction modes.
+type TransactionModeCommand struct {
+	Type  TransactionModeCommandType
+	modes []transactionMode
+}
+
+// IsTransactionModeCommand returns true if the specified query is a transaction command with transaction modes.
+// BEGIN without transaction modes is not a transaction mode command because the SQL parser supports it.
+func IsTransactionModeCommand(q string) bool {
+	keyword, rest := nextWord(q)
+	switch strings.ToUpper(keyword) {
+	case startKeyword:
+		keyword, _ = nextWord(rest)
+		return strings.EqualFold(keyword, transactionKeyword)
+	case setKeyword:
+		keyword, _ = nextWord(rest)
+		return strings.EqualFold(keyword, transactionKeyword)
+	case beginKeyword:
+		keyword, after := nextWord(rest)
+		if strings.EqualFold(keyword, workKeyword) || strings.EqualFold(keyword, transactionKeyword) {
+			keyword, _ = nextWord(after)
+		}
+		return len(keyword) != 0
+	}
+	return false
+}
+
+// HasTransactionModeCommand returns true if the specified query has a transaction command with transaction modes.
+func HasTransactionModeCommand(q string) bool {
+	for _, s := range SplitQueries(q) {
+		if IsTransactionModeCommand(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsTransactionControlCommand returns true if the specified query is a transaction control command such as BEGIN, COMMIT and ROLLBACK,
+// including the savepoint commands, the transaction mode commands, and the two-phase commit commands.
+func IsTransactionControlCommand(q string) bool {
+	if IsSavepointCommand(q) || IsTransactionModeCommand(q) || IsPreparedTransactionCommand(q) {
+		return true
+	}
+	keyword, _ := nextWord(q)
+	switch strings.ToUpper(keyword) {
+	case beginKeyword, startKeyword, commitKeyword, endKeyword, rollbackKeyword, abortKeyword:
+		return true
+	}
+	return false
+}
+
+// NewTransactionModeCommandFrom returns a transaction mode command of the specified query.
+func NewTransactionModeCommandFrom(q string) (*TransactionModeCommand, error) {
+	keyword, rest := nextWord(q)
+	var cmdType TransactionModeCommandType
+	switch strings.ToUpper(keyword) {
+	case beginKeyword:
+		// BEGIN [ WORK | TRANSACTION ] [ transaction_mode [, ...] ]
+		cmdType = BeginTransactionCommand
+		if keyword, after := nextWord(rest); strings.EqualFold(keyword, workKeyword) || strings.EqualFold(keyword, transactionKeyword) {
+			rest = after
+		}
+	case startKeyword, setKeyword:
+		// START TRANSACTION [ transaction_mode [, ...] ]
+		// SET TRANSACTION transaction_mode [, ...]
+		cmdType = BeginTransactionCommand
+		if strings.EqualFold(keyword, setKeyword) {
+			cmdType = SetTransactionCommand
+		}
+		keyword, after := nextWord(rest)
+		if !strings.EqualFold(keyword, transactionKeyword) {
+			return nil, errors.NewErrSyntax(errors.NewErrInvalid(q))
+		}
+		rest = after
+	default:
+		return nil, errors.NewErrSyntax(errors.NewErrNotSupported(q))
+	}
+
+	modes, err := newTransactionModesFrom(rest)
+	if err != nil {
+		return nil, errors.NewErrSyntax(err)
+	}
+	if cmdType == SetTransactionCommand && len(modes) == 0 {
+		return nil, errors.NewErrSyntax(errors.NewErrInvalid(q))
+	}
+
+	return &TransactionModeCommand{
+		Type:  cmdType,
+		modes: modes,
+	}, nil
+}
+
+// newTransactionModesFrom returns the transaction modes which are separated by commas or spaces.
+func newTransactionModesFrom(s string) ([]transactionMode, error) {
+	words := strings.FieldsFunc(strings.ToUpper(s), func(r rune) bool {
+		return r == ' ' || r == ',' || r == '\t' || r == '\n' || r == '\r'
+	})
+
+	nextWords := func(n int) []string {
+		if len(words) < n {
+			return nil
+		}
+		next := words[:n]
+		words = words[n:]
+		return next
+	}
+
+	modes := []transactionMode{}
+	for 0 < len(words) {
+		switch words[0] {
+		case isolationKeyword:
+			next := nextWords(3)
+			if next == nil || next[1] != levelKeyword {
+				return nil, errors.NewErrInvalid(s)
+			}
+			var level protocol.IsolationLevel
+			switch next[2] {
+			case serializableKeyword:
+				level = protocol.SerializableIsolationLevel
+			case repeatableKeyword, readKeyword:
+				next = append(next, nextWords(1)...)
+				switch {
+				case len(next) != 4:
+					return nil, errors.NewErrInvalid(s)
+				case next[2] == repeatableKeyword && next[3] == readKeyword:
+					level = protocol.RepeatableReadIsolationLevel
+				case next[2] == readKeyword && next[3] == committedKeyword:
+					level = protocol.ReadCommittedIsolationLevel
+				case next[2] == readKeyword && next[3] == uncommittedKeyword:
+					level = protocol.ReadUncommittedIsolationLevel
+				default:
+					return nil, errors.NewErrInvalid(s)
+				}
+			default:
+				return nil, errors.NewErrInvalid(s)
+			}
+			modes = append(modes, func(opts *protocol.TransactionOptions) {
+				opts.IsolationLevel = level
+			})
+		case readKeyword:
+			next := nextWords(2)
+			if next == nil {
+				return nil, errors.NewErrInvalid(s)
+			}
+			var mode protocol.AccessMode
+			switch next[1] {
+			case writeKeyword:
+				mode = protocol.ReadWriteAccessMode
+			case onlyKeyword:
+				mode = protocol.ReadOnlyAccessMode
+			default:
+				return nil, errors.NewErrInvalid(s)
+			}
+			modes = append(modes, func(opts *protocol.TransactionOptions) {
+				opts.AccessMode = mode
+			})
+		case deferrableKeyword:
+			nextWords(1)
+			modes = append(modes, func(opts *protocol.TransactionOptions) {
+				opts.Deferrable = true
+			})
+		case notKeyword:
+			next := nextWords(2)
+			if next == nil || next[1] != deferrableKeyword {
+				return nil, errors.NewErrInvalid(s)
+			}
+			modes = append(modes, func(opts *protocol.TransactionOptions) {
+				opts.Deferrable = false
+			})
+		default:
+			return nil, errors.NewErrInvalid(s)
+		}
+	}
+	return modes, nil
+}
+
+// Apply returns the specified transaction options which are updated with the transaction modes of the command.
+func (cmd *TransactionModeCommand) Apply(opts protocol.TransactionOptions) protocol.TransactionOptions {
+	for _, mode := range cmd.modes {
+		mode(&opts)
+	}
+	return opts
+}
+
+// TransactionCommand returns the transaction command of the command for the transaction state.
+func (cmd *TransactionModeCommand) TransactionCommand() protocol.TransactionCommand {
+	if cmd.Type == BeginTransactionCommand {
+		return protocol.TransactionBeginCommand
+	}
+	return protocol.TransactionNoneCommand
+}
+
+// CommandTag returns the tag of the CommandComplete message of the command.
+func (cmd *TransactionModeCommand) CommandTag() string {
+	switch cmd.Type {
+	case BeginTransactionCommand:
+		return beginKeyword
+	case SetTransactionCommand:
+		return setKeyword
+	}
+	return ""
+}
+
+// String returns the statement name of the command.
+func (cmd *TransactionModeCommand) String() string {
+	switch cmd.Type {
+	case BeginTransactionCommand:
+		return beginKeyword
+	case SetTransactionCommand:
+		return setKeyword + " " + transactionKeyword
+	}
+	return ""
+}
@@ -0,0 +1,108 @@
+// Copyright (C) 2019 The go-postgresql Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stmt
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/cybergarage/go-postgresql/postgresql/protocol"
+)
+
+func TestTransactionModeCommand(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{"BEGIN ISOLATION LEVEL SERIALIZABLE", "0 SERIALIZABLE  false"},
+		{"begin transaction read only", "0  READ ONLY false"},
+		{"BEGIN WORK ISOLATION LEVEL REPEATABLE READ, READ WRITE", "0 REPEATABLE READ READ WRITE false"},
+		{"START TRANSACTION", "0   false"},
+		{"START TRANSACTION ISOLATION LEVEL SERIALIZABLE READ ONLY DEFERRABLE", "0 SERIALIZABLE READ ONLY true"},
+		{"SET TRANSACTION ISOLATION LEVEL READ COMMITTED", "1 READ COMMITTED  false"},
+		{"SET TRANSACTION ISOLATION LEVEL READ UNCOMMITTED, NOT DEFERRABLE", "1 READ UNCOMMITTED  false"},
+	}
+
+	for _, test := range tests {
+		if !IsTransactionModeCommand(test.query) {
+			t.Errorf("%s is not a transaction mode command", test.query)
+			continue
+		}
+		cmd, err := NewTransactionModeCommandFrom(test.query)
+		if err != nil {
+			t.Errorf("%s: %s", test.query, err)
+			continue
+		}
+		opts := cmd.Apply(protocol.NewTransactionOptions())
+		actual := fmt.Sprintf("%d %s %s %t", cmd.Type, opts.IsolationLevel, opts.AccessMode, opts.Deferrable)
+		if actual != test.expected {
+			t.Errorf("%s: %s != %s", test.query, actual, test.expected)
+		}
+	}
+
+	// SET TRANSACTION updates only the specified characteristics.
+
+	cmd, err := NewTransactionModeCommandFrom("SET TRANSACTION READ ONLY")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	opts := cmd.Apply(protocol.TransactionOptions{IsolationLevel: protocol.SerializableIsolationLevel, AccessMode: protocol.ReadWriteAccessMode, Deferrable: true})
+	if opts.IsolationLevel != protocol.SerializableIsolationLevel || !opts.IsReadOnly() || !opts.Deferrable {
+		t.Errorf("%v", opts)
+	}
+
+	for _, query := range []string{"BEGIN", "BEGIN TRANSACTION", "SET search_path TO public", "START"} {
+		if IsTransactionModeCommand(query) {
+			t.Errorf("%s is a transaction mode command", query)
+		}
+	}
+
+	for _, query := range []string{"BEGIN ISOLATION LEVEL", "BEGIN READ", "BEGIN ISOLATION LEVEL REPEATABLE", "SET TRANSACTION", "SET TRANSACTION SNAPSHOT '00000003-0000001B-1'", "BEGIN NOT READ"} {
+		if _, err := NewTransactionModeCommandFrom(query); err == nil {
+			t.Errorf("%s: Expected a syntax error", query)
+		}
+	}
+}
+
+func TestTransactionControlCommand(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected bool
+	}{
+		{"BEGIN", true},
+		{"begin isolation level serializable", true},
+		{"START TRANSACTION", true},
+		{"COMMIT", true},
+		{"END", true},
+		{"ROLLBACK", true},
+		{"ABORT", true},
+		{"SAVEPOINT sp1", true},
+		{"ROLLBACK TO SAVEPOINT sp1", true},
+		{"SET TRANSACTION READ ONLY", true},
+		{"PREPARE TRANSACTION 'gid'", true},
+		{"COMMIT PREPARED 'gid'", true},
+		{"PREPARE p AS SELECT 1", false},
+		{"EXECUTE p", false},
+		{"SELECT * FROM t", false},
+		{"INSERT INTO t (k) VALUES ('BEGIN')", false},
+	}
+
+	for _, test := range tests {
+		if IsTransactionControlCommand(test.query) != test.expected {
+			t.Errorf("%s != %t", test.query, test.expected)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright (C) 2019 The go-postgresql Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package system
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cybergarage/go-sqlparser/sql/query"
+	"github.com/cybergarage/go-sqlparser/sql/query/response/resultset"
+)
+
+// PostgreSQL: Documentation: 16: 54.16. pg_prepared_xacts
+// https://www.postgresql.org/docs/16/view-pg-prepared-xacts.html
+
+const (
+	// PreparedXacts represents the system view name of the prepared transactions.
+	PreparedXacts = "pg_prepared_xacts"
+)
+
+const (
+	PreparedXactsTransaction = "transaction"
+	PreparedXactsGID         = "gid"
+	PreparedXactsPrepared    = "prepared"
+	PreparedXactsOwner       = "owner"
+	PreparedXactsDatabase    = "database"
+)
+
+// PreparedXact represents a transaction which is prepared for two-phase commit.
+type PreparedXact struct {
+	// Transaction is the numeric transaction identifier of the prepared transaction.
+	Transaction int
+	// GID is the global transaction identifier which was assigned to the transaction.
+	GID string
+	// Prepared is the time at which the transaction was prepared for commit.
+	Prepared time.Time
+	// Owner is the name of the user that executed the transaction.
+	Owner string
+	// Database is the name of the database in which the transaction was executed.
+	Database string
+}
+
+// IsPreparedXactsQuery returns true if the specified SELECT query refers to the pg_prepared_xacts view.
+func IsPreparedXactsQuery(stmt query.Select) bool {
+	from := stmt.From()
+	if len(from) != 1 {
+		return false
+	}
+	table := from[0]
+	return table.IsFullTableName(PreparedXacts) ||
+		table.IsFullTableName(SystemSchemaName+query.TableNameSep+PreparedXacts)
+}
+
+// NewPreparedXactsSchemaFor returns the result set schema of the specified SELECT query for the pg_prepared_xacts view.
+func NewPreparedXactsSchemaFor(stmt query.Select) (resultset.Schema, error) {
+	columnDefs := []struct {
+		name     string
+		dataType resultset.DataType
+	}{
+		{PreparedXactsTransaction, query.IntegerType},
+		{PreparedXactsGID, query.TextType},
+		{PreparedXactsPrepared, query.TimeStampType},
+		{PreparedXactsOwner, query.NameType},
+		{PreparedXactsDatabase, query.NameType},
+	}
+
+	columns := []resultset.Column{}
+	selectors := stmt.Selectors()
+	if selectors.IsAsterisk() {
+		for _, columnDef := range columnDefs {
+			columns = append(columns, resultset.NewColumn(
+				resultset.WithColumnName(columnDef.name),
+				resultset.WithColumnType(columnDef.dataType),
+			))
+		}
+	} else {
+		for _, selector := range selectors {
+			found := false
+			for _, columnDef := range columnDefs {
+				if !strings.EqualFold(selector.Name(), columnDef.name) {
+					continue
+				}
+				columns = append(columns, resultset.NewColumn(
+					resultset.WithColumnName(columnDef.name),
+					resultset.WithColumnType(columnDef.dataType),
+				))
+				found = true
+				break
+			}
+			if !found {
+				return nil, fmt.Errorf("%w selector: %s", ErrNotSupported, selector.Name())
+			}
+		}
+	}
+
+	return resultset.NewSchemaFrom(
+		resultset.WithSchemaDatabaseName(SystemDatabaseName),
+		resultset.WithSchemaTableName(PreparedXacts),
+		resultset.WithSchemaColumns(columns),
+	)
+}
+
+// NewPreparedXactsResultSetFrom returns the result set of the specified SELECT query for the pg_prepared_xacts view.
+// The result set has all of the specified prepared transactions because the view does not support the WHERE clause.
+func NewPreparedXactsResultSetFrom(stmt query.Select, xacts []*PreparedXact) (resultset.ResultSet, error) {
+	schema, err := NewPreparedXactsSchemaFor(stmt)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := []resultset.Row{}
+	for _, xact := range xacts {
+		obj := map[string]any{}
+		for _, column := range schema.Columns() {
+			switch column.Name() {
+			case PreparedXactsTransaction:
+				obj[PreparedXactsTransaction] = xact.Transaction
+			case PreparedXactsGID:
+				obj[PreparedXactsGID] = xact.GID
+			case PreparedXactsPrepared:
+				obj[PreparedXactsPrepared] = xact.Prepared
+			case PreparedXactsOwner:
+				obj[PreparedXactsOwner] = xact.Owner
+			case PreparedXactsDatabase:
+				obj[PreparedXactsDatabase] = xact.Database
+			}
+		}
+		rows = append(rows, resultset.NewRow(
+			resultset.WithRowObject(obj),
+			resultset.WithRowSchema(schema),
+		))
+	}
+
+	return resultset.NewResultSet(
+		resultset.WithResultSetSchema(schema),
+		resultset.WithResultSetRowsAffected(uint(len(rows))),
+		resultset.WithResultSetRows(rows),
+	), nil
+}
@@ -101,6 +101,8 @@ const (
 func NewSchemaForSelect(selectQuery query.Select) (resultset.Schema, error) {
 	from := selectQuery.From()
 	switch {
+	case IsPreparedXactsQuery(selectQuery):
+		return NewPreparedXactsSchemaFor(selectQuery)
 	case len(from) == 0:
 		columns := []resultset.Column{}
 		for _, selector := range selectQuery.Selectors() {
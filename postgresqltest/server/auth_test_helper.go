@@ -0,0 +1,377 @@
+// Copyright (C) 2019 The go-postgresql Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/cybergarage/go-postgresql/postgresql"
+	"github.com/cybergarage/go-postgresql/postgresql/auth"
+	"github.com/cybergarage/go-postgresql/postgresql/protocol"
+	"github.com/cybergarage/go-sqltest/sqltest"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// RunSCRAMAuthenticatorTest tests the SCRAM-SHA-256 authentication with a stored verifier.
+func RunSCRAMAuthenticatorTest(t *testing.T, server *Server, testDBName string) {
+	t.Helper()
+
+	const (
+		username = "scramuser"
+		password = "scrampassword"
+	)
+
+	verifier, err := auth.NewSCRAMVerifierFromPassword(password)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	cred := auth.NewCredential(
+		auth.WithCredentialUsername(username),
+		auth.WithCredentialPassword(verifier.String()),
+	)
+	server.SetCredential(cred)
+	server.SetAuthMethod(auth.MethodSCRAMSHA256)
+	defer func() {
+		server.SetCredentialStore(nil)
+		server.SetAuthMethod(auth.MethodPassword)
+	}()
+
+	newClient := func(password string) *postgresql.PgxClient {
+		client := postgresql.NewPgxClient()
+		client.SetUser(username)
+		client.SetPassword(password)
+		client.SetDatabase(testDBName)
+		client.SetAuth(sqltest.AuthSCRAMSHA256)
+		return client
+	}
+
+	// The explicitly selected method is not downgraded without a credential store.
+
+	err = pingClient(newClient(password))
+	if err == nil {
+		t.Errorf("%s is accepted without a credential store", auth.MethodSCRAMSHA256)
+	}
+
+	server.SetCredentialStore(server)
+
+	err = pingClient(newClient(password))
+	if err != nil {
+		t.Error(err)
+	}
+
+	err = pingClient(newClient("invalid" + password))
+	if err == nil {
+		t.Errorf("invalid password is accepted")
+	}
+}
+
+// RunSCRAMChannelBindingTest tests the SCRAM-SHA-256-PLUS authentication over a TLS connection.
+func RunSCRAMChannelBindingTest(t *testing.T, server *Server, testDBName string) {
+	t.Helper()
+
+	const (
+		username   = "scramplususer"
+		password   = "scrampluspassword"
+		clientKey  = "../certs/client-key.pem"
+		clientCert = "../certs/client-cert.pem"
+	)
+
+	cred := auth.NewCredential(
+		auth.WithCredentialUsername(username),
+		auth.WithCredentialPassword(password),
+	)
+	server.SetCredential(cred)
+	server.SetCredentialStore(server)
+	server.SetAuthMethod(auth.MethodSCRAMSHA256)
+	server.SetClientAuthType(tls.NoClientCert)
+	defer func() {
+		server.SetCredentialStore(nil)
+		server.SetAuthMethod(auth.MethodPassword)
+		server.SetClientAuthType(tls.RequireAndVerifyClientCert)
+	}()
+
+	// pgx prefers SCRAM-SHA-256-PLUS when the server advertises it over TLS.
+	client := postgresql.NewPgxClient()
+	client.SetUser(username)
+	client.SetPassword(password)
+	client.SetDatabase(testDBName)
+	client.SetAuth(sqltest.AuthSCRAMSHA256)
+	client.SetClientKeyFile(clientKey)
+	client.SetClientCertFile(clientCert)
+	err := pingClient(client)
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+// RunMD5AuthenticatorTest tests the MD5 password authentication with a stored MD5 hashed password.
+func RunMD5AuthenticatorTest(t *testing.T, server *Server, testDBName string) {
+	t.Helper()
+
+	const (
+		username = "md5user"
+		password = "md5password"
+	)
+
+	cred := auth.NewCredential(
+		auth.WithCredentialUsername(username),
+		auth.WithCredentialPassword(auth.NewMD5Password(username, password)),
+	)
+	server.SetCredential(cred)
+	server.SetCredentialStore(server)
+	server.SetAuthMethod(auth.MethodMD5)
+	defer func() {
+		server.SetCredentialStore(nil)
+		server.SetAuthMethod(auth.MethodPassword)
+	}()
+
+	newClient := func(password string) *postgresql.PgxClient {
+		client := postgresql.NewPgxClient()
+		client.SetUser(username)
+		client.SetPassword(password)
+		client.SetDatabase(testDBName)
+		client.SetAuth(sqltest.AuthMD5)
+		return client
+	}
+
+	err := pingClient(newClient(password))
+	if err != nil {
+		t.Error(err)
+	}
+
+	err = pingClient(newClient("invalid" + password))
+	if err == nil {
+		t.Errorf("invalid password is accepted")
+	}
+}
+
+// RunAuthMethodPolicyTest tests the authentication method policy.
+func RunAuthMethodPolicyTest(t *testing.T, server *Server, testDBName string) {
+	t.Helper()
+
+	const (
+		trustUser  = "trustuser"
+		rejectUser = "rejectuser"
+	)
+
+	server.SetAuthMethodPolicy(postgresql.AuthMethodPolicyFunc(
+		func(conn postgresql.Conn, msg *protocol.Startup) (auth.Method, error) {
+			user, _ := msg.User()
+			switch user {
+			case trustUser:
+				return auth.MethodTrust, nil
+			case rejectUser:
+				return auth.MethodReject, nil
+			}
+			return auth.MethodSCRAMSHA256, nil
+		}))
+	defer func() {
+		server.SetAuthMethodPolicy(nil)
+	}()
+
+	newClient := func(user string) *postgresql.PgxClient {
+		client := postgresql.NewPgxClient()
+		client.SetUser(user)
+		client.SetDatabase(testDBName)
+		return client
+	}
+
+	err := pingClient(newClient(trustUser))
+	if err != nil {
+		t.Error(err)
+	}
+
+	err = pingClient(newClient(rejectUser))
+	if err == nil {
+		t.Errorf("%s is accepted", rejectUser)
+	}
+}
+
+// RunHBATest tests the host-based authentication rules.
+func RunHBATest(t *testing.T, server *Server, testDBName string) {
+	t.Helper()
+
+	const (
+		trustUser   = "trustuser"
+		rejectUser  = "rejectuser"
+		noEntryUser = "noentryuser"
+	)
+
+	hba, err := auth.NewHBAFromString(fmt.Sprintf(`
+# TYPE  DATABASE  USER      ADDRESS       METHOD
+host    %s        %s        127.0.0.1/32  trust
+host    %s        %s        ::1/128       trust
+host    all       %s        all           reject
+host    all       %s        10.0.0.0/8    trust
+`, testDBName, trustUser, testDBName, trustUser, rejectUser, noEntryUser))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	server.SetHBA(hba)
+	defer func() {
+		server.SetHBA(nil)
+	}()
+
+	newClient := func(user string) *postgresql.PgxClient {
+		client := postgresql.NewPgxClient()
+		client.SetUser(user)
+		client.SetDatabase(testDBName)
+		return client
+	}
+
+	err = pingClient(newClient(trustUser))
+	if err != nil {
+		t.Error(err)
+	}
+
+	for _, user := range []string{rejectUser, noEntryUser} {
+		err = pingClient(newClient(user))
+		if err == nil {
+			t.Errorf("%s is accepted", user)
+			continue
+		}
+		var pgErr *pgconn.PgError
+		if !errors.As(err, &pgErr) {
+			t.Errorf("%s: %s", user, err)
+			continue
+		}
+		if pgErr.Severity != "FATAL" || pgErr.Code != "28000" {
+			t.Errorf("%s: %s (%s)", user, pgErr.Severity, pgErr.Code)
+		}
+	}
+}
+
+// RunIdentMapTest tests the certificate authentication with the user name maps.
+func RunIdentMapTest(t *testing.T, server *Server, testDBName string) {
+	t.Helper()
+
+	const (
+		clientKey  = "../certs/client-key.pem"
+		clientCert = "../certs/client-cert.pem"
+	)
+
+	// The common name and the DNS name of the client certificate are "localhost".
+	hba, err := auth.NewHBAFromString("hostssl all all all cert map=certmap")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	identMap, err := auth.NewIdentMapFromString(`
+# MAPNAME  SYSTEM-USERNAME  PG-USERNAME
+certmap    localhost        certuser
+certmap    /^(.*)host$      \1user
+`)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	server.SetHBA(hba)
+	server.SetIdentMap(identMap)
+	server.SetClientAuthType(tls.RequireAnyClientCert)
+	defer func() {
+		server.SetHBA(nil)
+		server.SetIdentMap(nil)
+		server.SetClientAuthType(tls.RequireAndVerifyClientCert)
+	}()
+
+	newClient := func(user string) *postgresql.PgxClient {
+		client := postgresql.NewPgxClient()
+		client.SetUser(user)
+		client.SetDatabase(testDBName)
+		client.SetClientKeyFile(clientKey)
+		client.SetClientCertFile(clientCert)
+		return client
+	}
+
+	for _, user := range []string{"certuser", "localuser"} {
+		err = pingClient(newClient(user))
+		if err != nil {
+			t.Errorf("%s: %s", user, err)
+		}
+	}
+
+	for _, user := range []string{"localhost", "otheruser"} {
+		err = pingClient(newClient(user))
+		if err == nil {
+			t.Errorf("%s is accepted", user)
+		}
+	}
+}
+
+// RunDefaultIdentMapTest tests the certificate authentication with the default user name map without host-based authentication rules.
+func RunDefaultIdentMapTest(t *testing.T, server *Server, testDBName string) {
+	t.Helper()
+
+	const (
+		clientKey  = "../certs/client-key.pem"
+		clientCert = "../certs/client-cert.pem"
+	)
+
+	// The common name and the DNS name of the client certificate are "localhost".
+	identMap, err := auth.NewIdentMapFromString(`
+# MAPNAME  SYSTEM-USERNAME  PG-USERNAME
+default    localhost        certuser
+othermap   localhost        otheruser
+`)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	server.SetAuthMethod(auth.MethodCert)
+	server.SetClientAuthType(tls.RequireAnyClientCert)
+	defer func() {
+		server.SetAuthMethod(auth.MethodPassword)
+		server.SetIdentMap(nil)
+		server.SetClientAuthType(tls.RequireAndVerifyClientCert)
+	}()
+
+	newClient := func(user string) *postgresql.PgxClient {
+		client := postgresql.NewPgxClient()
+		client.SetUser(user)
+		client.SetDatabase(testDBName)
+		client.SetClientKeyFile(clientKey)
+		client.SetClientCertFile(clientCert)
+		return client
+	}
+
+	// The common name must be the same as the user name without the default map.
+	err = pingClient(newClient("localhost"))
+	if err != nil {
+		t.Errorf("localhost: %s", err)
+	}
+
+	server.SetIdentMap(identMap)
+
+	err = pingClient(newClient("certuser"))
+	if err != nil {
+		t.Errorf("certuser: %s", err)
+	}
+
+	for _, user := range []string{"localhost", "otheruser"} {
+		err = pingClient(newClient(user))
+		if err == nil {
+			t.Errorf("%s is accepted", user)
+		}
+	}
+}
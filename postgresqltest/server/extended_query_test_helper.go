@@ -0,0 +1,487 @@
+// Copyright (C) 2019 The go-postgresql Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	pgx "github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgproto3"
+)
+
+// RunPortalSuspendedTest tests the row limit of Execute messages and the resumption of the suspended portal.
+func RunPortalSuspendedTest(t *testing.T, server *Server, testDBName string) {
+	t.Helper()
+
+	queries := []string{
+		"CREATE TABLE portaltest (k INTEGER PRIMARY KEY, v INTEGER)",
+	}
+	for n := 1; n <= 5; n++ {
+		queries = append(queries, fmt.Sprintf("INSERT INTO portaltest (k, v) VALUES (%d, %d)", n, n))
+	}
+	conn, ok := connectTestDatabase(t, testDBName, queries...)
+	if !ok {
+		return
+	}
+	defer conn.Close(context.Background())
+
+	// Executes the portal with the row limit until the portal is completed.
+
+	frontend := conn.Frontend()
+	frontend.SendParse(&pgproto3.Parse{Name: "", Query: "SELECT * FROM portaltest"})
+	frontend.SendBind(&pgproto3.Bind{DestinationPortal: "", PreparedStatement: ""})
+	for range 3 {
+		frontend.SendExecute(&pgproto3.Execute{Portal: "", MaxRows: 2})
+	}
+	frontend.SendSync(&pgproto3.Sync{})
+	err := frontend.Flush()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	rows := []int{}
+	nRows := 0
+	nSuspended := 0
+	isCompleted := false
+	for !isCompleted {
+		msg, err := frontend.Receive()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		switch msg := msg.(type) {
+		case *pgproto3.DataRow:
+			nRows++
+		case *pgproto3.PortalSuspended:
+			rows = append(rows, nRows)
+			nRows = 0
+			nSuspended++
+		case *pgproto3.CommandComplete:
+			rows = append(rows, nRows)
+		case *pgproto3.ErrorResponse:
+			t.Errorf("%s (%s)", msg.Message, msg.Code)
+			return
+		case *pgproto3.ReadyForQuery:
+			isCompleted = true
+		}
+	}
+
+	if nSuspended != 2 {
+		t.Errorf("portal suspended (%d) != 2", nSuspended)
+	}
+	if fmt.Sprintf("%v", rows) != "[2 2 1]" {
+		t.Errorf("rows %v != [2 2 1]", rows)
+	}
+}
+
+// RunExtendedQueryErrorTest tests that the messages after an error are discarded until the next Sync.
+func RunExtendedQueryErrorTest(t *testing.T, server *Server, testDBName string) {
+	t.Helper()
+
+	conn, ok := connectTestDatabase(t, testDBName)
+	if !ok {
+		return
+	}
+	defer conn.Close(context.Background())
+
+	frontend := conn.Frontend()
+	frontend.SendParse(&pgproto3.Parse{Name: "", Query: "SELEKT 1"})
+	frontend.SendBind(&pgproto3.Bind{DestinationPortal: "", PreparedStatement: ""})
+	frontend.SendDescribe(&pgproto3.Describe{ObjectType: 'P', Name: ""})
+	frontend.SendExecute(&pgproto3.Execute{Portal: "", MaxRows: 0})
+	frontend.SendSync(&pgproto3.Sync{})
+	err := frontend.Flush()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	codes := []string{}
+	isReady := false
+	for !isReady {
+		msg, err := frontend.Receive()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		switch msg := msg.(type) {
+		case *pgproto3.ErrorResponse:
+			codes = append(codes, msg.Code)
+		case *pgproto3.ReadyForQuery:
+			isReady = true
+		default:
+			t.Errorf("unexpected message %T", msg)
+		}
+	}
+
+	if len(codes) != 1 || codes[0] != "42601" {
+		t.Errorf("error codes %v != [42601]", codes)
+	}
+
+	// The connection returns to the normal message processing after the Sync.
+
+	err = conn.Ping(context.Background())
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+// RunReadyForQueryTest tests that ReadyForQuery is sent for each pipelined Query and Sync message.
+func RunReadyForQueryTest(t *testing.T, server *Server, testDBName string) {
+	t.Helper()
+
+	conn, ok := connectTestDatabase(t, testDBName,
+		"CREATE TABLE readytest (k INTEGER PRIMARY KEY, v INTEGER)",
+	)
+	if !ok {
+		return
+	}
+	defer conn.Close(context.Background())
+
+	frontend := conn.Frontend()
+	for range 2 {
+		frontend.Send(&pgproto3.Query{String: "SELECT * FROM readytest"})
+	}
+	for range 2 {
+		frontend.SendParse(&pgproto3.Parse{Name: "", Query: "SELECT * FROM readytest"})
+		frontend.SendBind(&pgproto3.Bind{DestinationPortal: "", PreparedStatement: ""})
+		frontend.SendExecute(&pgproto3.Execute{Portal: "", MaxRows: 0})
+		frontend.SendSync(&pgproto3.Sync{})
+	}
+	err := frontend.Flush()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	err = conn.Conn().SetReadDeadline(time.Now().Add(5 * time.Second))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer conn.Conn().SetReadDeadline(time.Time{})
+
+	// Receives the responses until all ReadyForQuery messages are received.
+
+	nReady := 0
+	nCompleted := 0
+	for nReady < 4 {
+		msg, err := frontend.Receive()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		switch msg := msg.(type) {
+		case *pgproto3.CommandComplete:
+			nCompleted++
+		case *pgproto3.ErrorResponse:
+			t.Errorf("%s (%s)", msg.Message, msg.Code)
+		case *pgproto3.ReadyForQuery:
+			nReady++
+			if nCompleted != nReady {
+				t.Errorf("command complete (%d) != ready for query (%d)", nCompleted, nReady)
+			}
+		}
+	}
+}
+
+// RunFlushTest tests that the Flush message delivers the buffered responses without a Sync message.
+func RunFlushTest(t *testing.T, server *Server, testDBName string) {
+	t.Helper()
+
+	conn, ok := connectTestDatabase(t, testDBName,
+		"CREATE TABLE flushtest (k INTEGER PRIMARY KEY, v INTEGER)",
+	)
+	if !ok {
+		return
+	}
+	defer conn.Close(context.Background())
+
+	frontend := conn.Frontend()
+	frontend.SendParse(&pgproto3.Parse{Name: "flushstmt", Query: "SELECT * FROM flushtest"})
+	frontend.Send(&pgproto3.Flush{})
+	err := frontend.Flush()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	err = conn.Conn().SetReadDeadline(time.Now().Add(5 * time.Second))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	msg, err := frontend.Receive()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if _, ok := msg.(*pgproto3.ParseComplete); !ok {
+		t.Errorf("unexpected message %T", msg)
+	}
+
+	err = conn.Conn().SetReadDeadline(time.Time{})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	// Completes the extended query cycle.
+
+	frontend.SendSync(&pgproto3.Sync{})
+	err = frontend.Flush()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	msg, err = frontend.Receive()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if _, ok := msg.(*pgproto3.ReadyForQuery); !ok {
+		t.Errorf("unexpected message %T", msg)
+	}
+}
+
+// RunBinaryResultFormatTest tests that the DataRow columns are encoded in the result-column format codes of the Bind message.
+func RunBinaryResultFormatTest(t *testing.T, server *Server, testDBName string) {
+	t.Helper()
+
+	conn, ok := connectTestDatabase(t, testDBName,
+		"CREATE TABLE binfmttest (k INTEGER PRIMARY KEY, v TEXT)",
+		"INSERT INTO binfmttest (k, v) VALUES (258, 'abc')",
+	)
+	if !ok {
+		return
+	}
+	defer conn.Close(context.Background())
+
+	tests := []struct {
+		resultFormats []int16
+		expected      [][]byte
+	}{
+		{nil, [][]byte{[]byte("258"), []byte("abc")}},
+		{[]int16{1}, [][]byte{{0x00, 0x00, 0x01, 0x02}, []byte("abc")}},
+		{[]int16{1, 0}, [][]byte{{0x00, 0x00, 0x01, 0x02}, []byte("abc")}},
+	}
+
+	frontend := conn.Frontend()
+	for _, test := range tests {
+		frontend.SendParse(&pgproto3.Parse{Name: "", Query: "SELECT k, v FROM binfmttest WHERE k = 258"})
+		frontend.SendBind(&pgproto3.Bind{DestinationPortal: "", PreparedStatement: "", ResultFormatCodes: test.resultFormats})
+		frontend.SendExecute(&pgproto3.Execute{Portal: "", MaxRows: 0})
+		frontend.SendSync(&pgproto3.Sync{})
+		err := frontend.Flush()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		values := [][]byte{}
+		isCompleted := false
+		for !isCompleted {
+			msg, err := frontend.Receive()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			switch msg := msg.(type) {
+			case *pgproto3.DataRow:
+				for _, v := range msg.Values {
+					values = append(values, append([]byte{}, v...))
+				}
+			case *pgproto3.ErrorResponse:
+				t.Errorf("%s (%s)", msg.Message, msg.Code)
+			case *pgproto3.ReadyForQuery:
+				isCompleted = true
+			}
+		}
+
+		if fmt.Sprintf("%v", values) != fmt.Sprintf("%v", test.expected) {
+			t.Errorf("%v: values %v != %v", test.resultFormats, values, test.expected)
+		}
+	}
+}
+
+// RunBinaryBindParamTest tests that the binary bind parameters are decoded with the parameter data types of the Parse message.
+func RunBinaryBindParamTest(t *testing.T, server *Server, testDBName string) {
+	t.Helper()
+
+	conn, ok := connectTestDatabase(t, testDBName,
+		"CREATE TABLE binparamtest (k INTEGER PRIMARY KEY, f FLOAT, v TEXT, n TEXT)",
+	)
+	if !ok {
+		return
+	}
+	defer conn.Close(context.Background())
+
+	// Inserts the row with the binary parameters of int4, float8, text and numeric.
+	// The numeric value keeps the precision because it is bound as the decimal string.
+
+	frontend := conn.Frontend()
+	frontend.SendParse(&pgproto3.Parse{
+		Name:          "",
+		Query:         "INSERT INTO binparamtest (k, f, v, n) VALUES ($1, $2, $3, $4)",
+		ParameterOIDs: []uint32{23, 701, 25, 1700},
+	})
+	frontend.SendBind(&pgproto3.Bind{
+		DestinationPortal:    "",
+		PreparedStatement:    "",
+		ParameterFormatCodes: []int16{1},
+		Parameters: [][]byte{
+			{0x00, 0x00, 0x01, 0x02},
+			{0x3F, 0xF8, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+			[]byte("abc"),
+			{0x00, 0x08, 0x00, 0x04, 0x00, 0x00, 0x00, 0x0A, 0x04, 0xD2, 0x16, 0x2E, 0x23, 0x34, 0x0D, 0x80, 0x1E, 0xD2, 0x00, 0x7B, 0x11, 0xD7, 0x22, 0xC4},
+		},
+	})
+	frontend.SendExecute(&pgproto3.Execute{Portal: "", MaxRows: 0})
+	frontend.SendSync(&pgproto3.Sync{})
+	err := frontend.Flush()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	isCompleted := false
+	for !isCompleted {
+		msg, err := frontend.Receive()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		switch msg := msg.(type) {
+		case *pgproto3.ErrorResponse:
+			t.Errorf("%s (%s)", msg.Message, msg.Code)
+		case *pgproto3.ReadyForQuery:
+			isCompleted = true
+		}
+	}
+
+	results, err := conn.Exec(context.Background(), "SELECT k, f, v, n FROM binparamtest WHERE k = 258").ReadAll()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(results) != 1 || len(results[0].Rows) != 1 {
+		t.Errorf("rows of binparamtest are not found")
+		return
+	}
+	values := []string{}
+	for _, v := range results[0].Rows[0] {
+		values = append(values, string(v))
+	}
+	expected := "[258 1.5 abc 12345678901234567890.0123456789]"
+	if fmt.Sprintf("%v", values) != expected {
+		t.Errorf("values %v != %s", values, expected)
+	}
+}
+
+// RunParameterDescriptionTest tests that the parameter data types of prepared statements are inferred from the table schema.
+func RunParameterDescriptionTest(t *testing.T, server *Server, testDBName string) {
+	t.Helper()
+
+	conn, ok := connectTestDatabase(t, testDBName,
+		"CREATE TABLE paramdesctest (k INTEGER PRIMARY KEY, f FLOAT, v TEXT)",
+	)
+	if !ok {
+		return
+	}
+	defer conn.Close(context.Background())
+
+	tests := []struct {
+		query    string
+		oids     []uint32
+		expected []uint32
+	}{
+		{"INSERT INTO paramdesctest (k, f, v) VALUES ($1, $2, $3)", nil, []uint32{23, 700, 25}},
+		{"INSERT INTO paramdesctest (k, f, v) VALUES ($1, $2, $3)", []uint32{20}, []uint32{20, 700, 25}},
+		{"UPDATE paramdesctest SET v = $1 WHERE k = $2", nil, []uint32{25, 23}},
+		{"DELETE FROM paramdesctest WHERE k = $1", nil, []uint32{23}},
+		{"SELECT * FROM paramdesctest WHERE k = $1 AND f > $2", nil, []uint32{23, 700}},
+		{"UPDATE paramdesctest SET f = $1 WHERE nosuchcolumn = $2", nil, []uint32{700, 25}},
+	}
+
+	for _, test := range tests {
+		psd, err := conn.Prepare(context.Background(), "", test.query, test.oids)
+		if err != nil {
+			t.Errorf("%s: %s", test.query, err)
+			continue
+		}
+		if fmt.Sprintf("%v", psd.ParamOIDs) != fmt.Sprintf("%v", test.expected) {
+			t.Errorf("%s: parameter types %v != %v", test.query, psd.ParamOIDs, test.expected)
+		}
+	}
+}
+
+// RunDescribeSelectTest tests that Describe messages return the RowDescription of user table SELECT queries.
+func RunDescribeSelectTest(t *testing.T, server *Server, testDBName string) {
+	t.Helper()
+
+	conn, err := pgx.Connect(context.Background(), testDatabaseURL(testDBName))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer conn.Close(context.Background())
+
+	queries := []string{
+		"CREATE TABLE describetest (k INTEGER PRIMARY KEY, f FLOAT, v TEXT)",
+		"INSERT INTO describetest (k, f, v) VALUES (1, 1.5, 'abc')",
+	}
+	for _, query := range queries {
+		_, err := conn.Exec(context.Background(), query, pgx.QueryExecModeSimpleProtocol)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+	}
+
+	// Describes the prepared statement.
+
+	psd, err := conn.Prepare(context.Background(), "describetest", "SELECT k, v FROM describetest WHERE k = $1")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	fields := []string{}
+	for _, field := range psd.Fields {
+		fields = append(fields, fmt.Sprintf("%s:%d", field.Name, field.DataTypeOID))
+	}
+	if fmt.Sprintf("%v", fields) != "[k:23 v:25]" {
+		t.Errorf("fields %v != [k:23 v:25]", fields)
+	}
+
+	// Queries the row with the described result columns which pgx receives in the binary format.
+
+	var k int32
+	var f float32
+	var v string
+	err = conn.QueryRow(context.Background(), "SELECT k, f, v FROM describetest WHERE k = $1", 1).Scan(&k, &f, &v)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if k != 1 || f != 1.5 || v != "abc" {
+		t.Errorf("row (%d, %f, %s) != (1, 1.5, abc)", k, f, v)
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright (C) 2019 The go-postgresql Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"testing"
+)
+
+// RunPreparedStatementTest tests the multi-statement prepared statements and the SQL-level PREPARE, EXECUTE and DEALLOCATE commands.
+func RunPreparedStatementTest(t *testing.T, server *Server, testDBName string) {
+	t.Helper()
+
+	conn, ok := connectTestDatabase(t, testDBName,
+		"CREATE TABLE preparetest (k INTEGER PRIMARY KEY, v TEXT)",
+	)
+	if !ok {
+		return
+	}
+	defer conn.Close(context.Background())
+
+	// Executes the multi-statement prepared statement of the extended query protocol.
+
+	_, err := conn.Prepare(context.Background(), "multi", "INSERT INTO preparetest (k, v) VALUES ($1, 'a'); INSERT INTO preparetest (k, v) VALUES ($2, 'b')", nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	res := conn.ExecPrepared(context.Background(), "multi", [][]byte{[]byte("1"), []byte("2")}, nil, nil).Read()
+	if res.Err != nil {
+		t.Error(res.Err)
+		return
+	}
+
+	// Executes the SQL-level prepared statements which share the namespace with the extended query protocol,
+	// and the deallocated prepared statements do not exist.
+
+	runCommandTagTests(t, conn, []commandTagTest{
+		{"PREPARE ins AS INSERT INTO preparetest (k, v) VALUES ($1, $2)", "", []string{"PREPARE"}},
+		{"EXECUTE ins(3, 'c'); EXECUTE ins (4, 'd')", "", []string{"INSERT 0 1", "INSERT 0 1"}},
+		{"PREPARE sel AS SELECT k, v FROM preparetest WHERE k = $1", "", []string{"PREPARE"}},
+		{"EXECUTE sel(4)", "", []string{"SELECT 1"}},
+		{"EXECUTE multi(5, 6)", "", []string{"INSERT 0 1", "INSERT 0 1"}},
+		{"SELECT k, v FROM preparetest", "", []string{"SELECT 6"}},
+		// The string parameters with quotes and backslashes are bound as the single string literals.
+		{"EXECUTE ins(7, 'O''Brien')", "", []string{"INSERT 0 1"}},
+		{"EXECUTE ins(8, 'x''); DELETE FROM preparetest; --')", "", []string{"INSERT 0 1"}},
+		{`EXECUTE ins(9, 'x\'); EXECUTE ins(10, 'y')`, "", []string{"INSERT 0 1", "INSERT 0 1"}},
+		{"EXECUTE sel(7); EXECUTE sel(8); EXECUTE sel(9)", "", []string{"SELECT 1", "SELECT 1", "SELECT 1"}},
+		{"SELECT k, v FROM preparetest", "", []string{"SELECT 10"}},
+		// The remaining statements are not executed after an error response.
+		{"EXECUTE ins(11, 'e'); SELEKT 1; EXECUTE ins(12, 'f')", "42601", nil},
+		{"EXECUTE ins(12, 'f'); INSERT INTO nosuchtable (k) VALUES (1); EXECUTE ins(13, 'g')", "42P01", nil},
+		{"SELECT k, v FROM preparetest", "", []string{"SELECT 12"}},
+	})
+
+	// The error responses abort the transaction block.
+
+	runTxStatusTests(t, conn, []txStatusTest{
+		{"BEGIN", "", 'T'},
+		{"EXECUTE ins(13, 'g'); SELEKT 1", "42601", 'E'},
+		{"EXECUTE ins(14, 'h')", "25P02", 'E'},
+		{"ROLLBACK", "", 'I'},
+	})
+
+	runCommandTagTests(t, conn, []commandTagTest{
+		{"DEALLOCATE ins; DEALLOCATE PREPARE sel", "", []string{"DEALLOCATE", "DEALLOCATE"}},
+		{"DEALLOCATE ALL", "", []string{"DEALLOCATE ALL"}},
+		{"EXECUTE multi(7, 8)", "26000", nil},
+		{"DEALLOCATE ins", "26000", nil},
+		{"PREPARE dup AS DELETE FROM preparetest; PREPARE dup AS DELETE FROM preparetest", "42P05", nil},
+	})
+}
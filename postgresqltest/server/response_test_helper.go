@@ -0,0 +1,305 @@
+// Copyright (C) 2019 The go-postgresql Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cybergarage/go-postgresql/postgresql"
+	"github.com/cybergarage/go-postgresql/postgresql/protocol"
+	"github.com/cybergarage/go-postgresql/postgresql/query"
+	"github.com/cybergarage/go-sqlparser/sql/query/response/resultset"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgproto3"
+)
+
+// RunErrorResponseTest tests the SQLSTATE code and severity of the error response.
+func RunErrorResponseTest(t *testing.T, server *Server, testDBName string) {
+	t.Helper()
+
+	conn, ok := connectTestDatabase(t, testDBName)
+	if !ok {
+		return
+	}
+	defer conn.Close(context.Background())
+
+	queries := []struct {
+		query string
+		code  string
+	}{
+		{"SELEKT 1", "42601"},
+		{"SELECT * FROM nosuchtable", "42P01"},
+		{"CREATE DATABASE " + testDBName, "42P04"},
+	}
+
+	for _, q := range queries {
+		_, err := conn.Exec(context.Background(), q.query).ReadAll()
+		var pgErr *pgconn.PgError
+		if !errors.As(err, &pgErr) {
+			t.Errorf("%s: %v", q.query, err)
+			continue
+		}
+		if pgErr.Severity != "ERROR" || pgErr.SeverityUnlocalized != "ERROR" || pgErr.Code != q.code {
+			t.Errorf("%s: %s %s (%s != %s)", q.query, pgErr.Severity, pgErr.SeverityUnlocalized, pgErr.Code, q.code)
+		}
+	}
+
+	err := conn.Ping(context.Background())
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+// RunNoticeResponseTest tests the notice response sent while executing a query.
+func RunNoticeResponseTest(t *testing.T, server *Server, testDBName string) {
+	t.Helper()
+
+	config, err := pgconn.ParseConfig(testDatabaseURL(testDBName))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	notices := []*pgconn.Notice{}
+	config.OnNotice = func(_ *pgconn.PgConn, notice *pgconn.Notice) {
+		notices = append(notices, notice)
+	}
+
+	conn, err := pgconn.ConnectConfig(context.Background(), config)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer conn.Close(context.Background())
+
+	_, err = conn.Exec(context.Background(), "DROP TABLE IF EXISTS nosuchtable").ReadAll()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if len(notices) != 1 {
+		t.Errorf("notices (%d) != 1", len(notices))
+		return
+	}
+	notice := notices[0]
+	if notice.Severity != "NOTICE" || notice.Code != "00000" {
+		t.Errorf("%s (%s) %s", notice.Severity, notice.Code, notice.Message)
+	}
+}
+
+// cancelTestExecutor represents a query executor which blocks SELECT queries until they are canceled.
+type cancelTestExecutor struct {
+	postgresql.QueryExecutor
+	started chan postgresql.Conn
+}
+
+// Select blocks until the query is canceled.
+func (executor *cancelTestExecutor) Select(conn postgresql.Conn, stmt query.Select) (protocol.Responses, error) {
+	executor.started <- conn
+	select {
+	case <-conn.Context().Done():
+		return nil, conn.Context().Err()
+	case <-time.After(5 * time.Second):
+		return nil, fmt.Errorf("%s is not canceled", stmt.String())
+	}
+}
+
+// RunCancelRequestTest tests the CancelRequest for the running query.
+func RunCancelRequestTest(t *testing.T, server *Server, testDBName string) {
+	t.Helper()
+
+	executor := &cancelTestExecutor{
+		QueryExecutor: server.QueryExecutor(),
+		started:       make(chan postgresql.Conn, 1),
+	}
+	server.SetQueryExecutor(executor)
+	defer func() {
+		server.SetQueryExecutor(executor.QueryExecutor)
+	}()
+
+	conn, ok := connectTestDatabase(t, testDBName)
+	if !ok {
+		return
+	}
+	defer conn.Close(context.Background())
+
+	otherConn, ok := connectTestDatabase(t, testDBName)
+	if !ok {
+		return
+	}
+	defer otherConn.Close(context.Background())
+
+	// Each connection has the unique backend key data.
+
+	if conn.PID() == otherConn.PID() || bytes.Equal(conn.SecretKey(), otherConn.SecretKey()) {
+		t.Errorf("backend key data (%d, %v) == (%d, %v)", conn.PID(), conn.SecretKey(), otherConn.PID(), otherConn.SecretKey())
+	}
+
+	go func() {
+		serverConn := <-executor.started
+		if serverConn.ProcessID() != int32(conn.PID()) {
+			t.Errorf("process id (%d) != %d", serverConn.ProcessID(), conn.PID())
+		}
+		err := conn.CancelRequest(context.Background())
+		if err != nil {
+			t.Error(err)
+		}
+	}()
+
+	_, err := conn.Exec(context.Background(), "SELECT * FROM canceltest").ReadAll()
+	if !hasSQLState(err, "57014") {
+		t.Errorf("%v (expected 57014)", err)
+	}
+
+	err = conn.Ping(context.Background())
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+// streamTestResultSet represents a result set which waits for the client to receive the first row
+// before producing the rest of the rows.
+type streamTestResultSet struct {
+	resultset.ResultSet
+	nRows    int
+	waitRows int
+	received chan struct{}
+	flushed  bool
+}
+
+// Next waits for the client to receive the first row after producing the specified number of rows.
+func (rs *streamTestResultSet) Next() bool {
+	if rs.nRows == rs.waitRows {
+		select {
+		case <-rs.received:
+			rs.flushed = true
+		case <-time.After(5 * time.Second):
+		}
+	}
+	if !rs.ResultSet.Next() {
+		return false
+	}
+	rs.nRows++
+	return true
+}
+
+// streamTestExecutor represents a query executor which streams the result sets of SELECT queries.
+type streamTestExecutor struct {
+	postgresql.QueryExecutor
+	sqlExecutor postgresql.SQLExecutor
+	rs          *streamTestResultSet
+}
+
+// SelectStream returns the result set stream which waits for the client to receive the first row.
+func (executor *streamTestExecutor) SelectStream(conn postgresql.Conn, stmt query.Select) (*query.ResultSetStream, error) {
+	rs, err := executor.sqlExecutor.Select(conn, stmt)
+	if err != nil {
+		return nil, err
+	}
+	executor.rs.ResultSet = rs
+	return query.NewResultSetStreamWith(executor.rs)
+}
+
+// RunResultSetStreamTest tests that the rows of a large result set are flushed to the client
+// while the result set is producing them, and that the CommandComplete is sent only once.
+func RunResultSetStreamTest(t *testing.T, server *Server, testDBName string) {
+	t.Helper()
+
+	// Inserts the rows which are larger than the response buffer.
+
+	nRows := 100
+	value := strings.Repeat("x", (protocol.DefaultWriteBufferSize*2)/nRows)
+	queries := []string{
+		"CREATE TABLE streamtest (k INTEGER PRIMARY KEY, v TEXT)",
+	}
+	for n := 1; n <= nRows; n++ {
+		queries = append(queries, fmt.Sprintf("INSERT INTO streamtest (k, v) VALUES (%d, '%s')", n, value))
+	}
+	conn, ok := connectTestDatabase(t, testDBName, queries...)
+	if !ok {
+		return
+	}
+	defer conn.Close(context.Background())
+
+	executor := &streamTestExecutor{
+		QueryExecutor: server.QueryExecutor(),
+		sqlExecutor:   server.SQLExecutor(),
+		rs: &streamTestResultSet{
+			ResultSet: nil,
+			nRows:     0,
+			waitRows:  nRows - 1,
+			received:  make(chan struct{}),
+			flushed:   false,
+		},
+	}
+	server.SetQueryExecutor(executor)
+	defer func() {
+		server.SetQueryExecutor(executor.QueryExecutor)
+	}()
+
+	frontend := conn.Frontend()
+	frontend.SendQuery(&pgproto3.Query{String: "SELECT * FROM streamtest"})
+	err := frontend.Flush()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	nDataRows := 0
+	nCompletes := 0
+	for {
+		msg, err := frontend.Receive()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		isReady := false
+		switch msg := msg.(type) {
+		case *pgproto3.DataRow:
+			if nDataRows == 0 {
+				close(executor.rs.received)
+			}
+			nDataRows++
+		case *pgproto3.CommandComplete:
+			if string(msg.CommandTag) != fmt.Sprintf("SELECT %d", nRows) {
+				t.Errorf("%s != SELECT %d", msg.CommandTag, nRows)
+			}
+			nCompletes++
+		case *pgproto3.ErrorResponse:
+			t.Errorf("%s (%s)", msg.Message, msg.Code)
+		case *pgproto3.ReadyForQuery:
+			isReady = true
+		}
+		if isReady {
+			break
+		}
+	}
+
+	if !executor.rs.flushed {
+		t.Errorf("rows are not flushed before the result set is completed")
+	}
+	if nDataRows != nRows {
+		t.Errorf("rows (%d) != %d", nDataRows, nRows)
+	}
+	if nCompletes != 1 {
+		t.Errorf("command completes (%d) != 1", nCompletes)
+	}
+}
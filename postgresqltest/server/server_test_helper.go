@@ -15,6 +15,8 @@
 package server
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"testing"
 	"time"
@@ -23,6 +25,7 @@ import (
 	"github.com/cybergarage/go-postgresql/postgresql"
 	"github.com/cybergarage/go-postgresql/postgresql/auth"
 	pgx "github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
 const testDBNamePrefix = "pgtest"
@@ -39,6 +42,31 @@ func RunServerTests(t *testing.T, server *Server) {
 		fn   ServerTestFunc
 	}{
 		{"authenticator", RunPasswordAuthenticatorTest},
+		{"scram", RunSCRAMAuthenticatorTest},
+		{"scram-plus", RunSCRAMChannelBindingTest},
+		{"md5", RunMD5AuthenticatorTest},
+		{"auth-policy", RunAuthMethodPolicyTest},
+		{"hba", RunHBATest},
+		{"ident-map", RunIdentMapTest},
+		{"default-ident-map", RunDefaultIdentMapTest},
+		{"error-response", RunErrorResponseTest},
+		{"notice-response", RunNoticeResponseTest},
+		{"cancel-request", RunCancelRequestTest},
+		{"result-set-stream", RunResultSetStreamTest},
+		{"portal-suspended", RunPortalSuspendedTest},
+		{"extended-query-error", RunExtendedQueryErrorTest},
+		{"ready-for-query", RunReadyForQueryTest},
+		{"flush", RunFlushTest},
+		{"binary-result-format", RunBinaryResultFormatTest},
+		{"binary-bind-param", RunBinaryBindParamTest},
+		{"parameter-description", RunParameterDescriptionTest},
+		{"describe-select", RunDescribeSelectTest},
+		{"prepared-statement", RunPreparedStatementTest},
+		{"transaction-state", RunTransactionStateTest},
+		{"implicit-transaction", RunImplicitTransactionTest},
+		{"savepoint", RunSavepointTest},
+		{"transaction-mode", RunTransactionModeTest},
+		{"two-phase-commit", RunTwoPhaseCommitTest},
 		// {"tls", RunCertificateAuthenticatorTest},
 		// {"copy", TestServerCopy},
 	}
@@ -130,6 +158,111 @@ func RunPasswordAuthenticatorTest(t *testing.T, server *Server, testDBName strin
 	}
 }
 
+// testDatabaseURL returns the connection URL of the specified test database.
+func testDatabaseURL(testDBName string) string {
+	return fmt.Sprintf("postgres://localhost/%s?sslmode=disable", testDBName)
+}
+
+// connectTestDatabase connects to the specified test database and executes the specified queries to set up the test tables.
+// The caller must close the returned connection if it returns true.
+func connectTestDatabase(t *testing.T, testDBName string, queries ...string) (*pgconn.PgConn, bool) {
+	t.Helper()
+
+	conn, err := pgconn.Connect(context.Background(), testDatabaseURL(testDBName))
+	if err != nil {
+		t.Error(err)
+		return nil, false
+	}
+	for _, query := range queries {
+		_, err := conn.Exec(context.Background(), query).ReadAll()
+		if err != nil {
+			t.Errorf("%s: %s", query, err)
+			conn.Close(context.Background())
+			return nil, false
+		}
+	}
+	return conn, true
+}
+
+// pingClient opens the specified client, pings the server and closes the client.
+func pingClient(client *postgresql.PgxClient) error {
+	err := client.Open()
+	if err != nil {
+		return err
+	}
+	err = client.Ping()
+	if err != nil {
+		client.Close()
+		return err
+	}
+	return client.Close()
+}
+
+// hasSQLState returns true if the specified error is an error response with the specified SQLSTATE code.
+func hasSQLState(err error, code string) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == code
+}
+
+// txStatusTest represents a simple query with the expected SQLSTATE code and the expected transaction status after the query.
+type txStatusTest struct {
+	query    string
+	code     string
+	expected byte
+}
+
+// runTxStatusTests executes the specified simple queries in order, and checks the SQLSTATE codes and the transaction statuses.
+func runTxStatusTests(t *testing.T, conn *pgconn.PgConn, tests []txStatusTest) {
+	t.Helper()
+
+	for _, test := range tests {
+		_, err := conn.Exec(context.Background(), test.query).ReadAll()
+		if len(test.code) == 0 {
+			if err != nil {
+				t.Errorf("%s: %s", test.query, err)
+			}
+		} else if !hasSQLState(err, test.code) {
+			t.Errorf("%s: %v (expected %s)", test.query, err, test.code)
+		}
+		if conn.TxStatus() != test.expected {
+			t.Errorf("%s: %c != %c", test.query, conn.TxStatus(), test.expected)
+		}
+	}
+}
+
+// commandTagTest represents a simple query with the expected command tags, or the expected SQLSTATE code if the query fails.
+type commandTagTest struct {
+	query    string
+	code     string
+	expected []string
+}
+
+// runCommandTagTests executes the specified simple queries in order, and checks the command tags or the SQLSTATE codes.
+func runCommandTagTests(t *testing.T, conn *pgconn.PgConn, tests []commandTagTest) {
+	t.Helper()
+
+	for _, test := range tests {
+		results, err := conn.Exec(context.Background(), test.query).ReadAll()
+		if len(test.code) != 0 {
+			if !hasSQLState(err, test.code) {
+				t.Errorf("%s: %v (expected %s)", test.query, err, test.code)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %s", test.query, err)
+			continue
+		}
+		tags := []string{}
+		for _, result := range results {
+			tags = append(tags, result.CommandTag.String())
+		}
+		if fmt.Sprintf("%v", tags) != fmt.Sprintf("%v", test.expected) {
+			t.Errorf("%s: %v != %v", test.query, tags, test.expected)
+		}
+	}
+}
+
 // RunCertificateAuthenticatorTest tests the TLS session.
 // PostgreSQL: Documentation: 16: 34.19. SSL Support
 // https://www.postgresql.org/docs/current/libpq-ssl.html
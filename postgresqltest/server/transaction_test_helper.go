@@ -0,0 +1,367 @@
+// Copyright (C) 2019 The go-postgresql Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/cybergarage/go-postgresql/postgresql"
+	"github.com/cybergarage/go-postgresql/postgresql/protocol"
+	"github.com/cybergarage/go-postgresql/postgresql/query"
+	pgx "github.com/jackc/pgx/v5"
+)
+
+// RunTransactionStateTest tests that the statements in an aborted transaction block are rejected until the end of the block.
+func RunTransactionStateTest(t *testing.T, server *Server, testDBName string) {
+	t.Helper()
+
+	conn, ok := connectTestDatabase(t, testDBName,
+		"CREATE TABLE txstatetest (k INTEGER PRIMARY KEY, v INTEGER)",
+	)
+	if !ok {
+		return
+	}
+	defer conn.Close(context.Background())
+
+	runTxStatusTests(t, conn, []txStatusTest{
+		{"BEGIN", "", 'T'},
+		{"INSERT INTO txstatetest (k, v) VALUES (1, 1)", "", 'T'},
+		{"SELECT * FROM nosuchtable", "42P01", 'E'},
+		{"SELECT * FROM txstatetest", "25P02", 'E'},
+		{"BEGIN", "25P02", 'E'},
+		{"ROLLBACK", "", 'I'},
+		{"SELECT * FROM txstatetest", "", 'I'},
+		{"BEGIN; SELECT * FROM nosuchtable", "42P01", 'E'},
+		{"COMMIT", "", 'I'},
+		{"SELECT * FROM nosuchtable", "42P01", 'I'},
+		{"SELECT * FROM txstatetest", "", 'I'},
+	})
+
+	// The extended query protocol is also rejected in an aborted transaction block.
+
+	_, err := conn.Exec(context.Background(), "BEGIN; SELECT * FROM nosuchtable").ReadAll()
+	if err == nil {
+		t.Errorf("error is not returned")
+	}
+	res := conn.ExecParams(context.Background(), "SELECT * FROM txstatetest WHERE k = $1", [][]byte{[]byte("1")}, nil, nil, nil).Read()
+	if !hasSQLState(res.Err, "25P02") {
+		t.Errorf("%v (expected 25P02)", res.Err)
+	}
+	res = conn.ExecParams(context.Background(), "ROLLBACK", nil, nil, nil, nil).Read()
+	if res.Err != nil {
+		t.Error(res.Err)
+	}
+	if conn.TxStatus() != 'I' {
+		t.Errorf("%c != I", conn.TxStatus())
+	}
+}
+
+// RunImplicitTransactionTest tests that the statements of a simple query after a failed statement are not executed.
+func RunImplicitTransactionTest(t *testing.T, server *Server, testDBName string) {
+	t.Helper()
+
+	conn, ok := connectTestDatabase(t, testDBName,
+		"CREATE TABLE implicittxtest (k INTEGER PRIMARY KEY, v INTEGER)",
+	)
+	if !ok {
+		return
+	}
+	defer conn.Close(context.Background())
+
+	runCommandTagTests(t, conn, []commandTagTest{
+		{"INSERT INTO implicittxtest (k, v) VALUES (1, 1); INSERT INTO implicittxtest (k, v) VALUES (2, 2)", "", []string{"INSERT 0 1", "INSERT 0 1"}},
+	})
+
+	results, err := conn.Exec(context.Background(), "INSERT INTO implicittxtest (k, v) VALUES (3, 3); SELECT * FROM nosuchtable; INSERT INTO implicittxtest (k, v) VALUES (4, 4)").ReadAll()
+	if !hasSQLState(err, "42P01") {
+		t.Errorf("%v (expected 42P01)", err)
+	}
+	if len(results) != 1 || results[0].CommandTag.String() != "INSERT 0 1" {
+		t.Errorf("results %d != 1", len(results))
+	}
+	if conn.TxStatus() != 'I' {
+		t.Errorf("%c != I", conn.TxStatus())
+	}
+
+	// The statement after the failed statement is not executed.
+
+	runCommandTagTests(t, conn, []commandTagTest{
+		{"SELECT * FROM implicittxtest WHERE k = 4", "", []string{"SELECT 0"}},
+	})
+
+	// The queries which have the commands unsupported by the SQL parser are also executed in an implicit transaction
+	// unless they have transaction control commands.
+
+	executor := &txControlTestExecutor{
+		QueryExecutor: server.QueryExecutor(),
+		commands:      []string{},
+	}
+	server.SetQueryExecutor(executor)
+	defer func() {
+		server.SetQueryExecutor(executor.QueryExecutor)
+	}()
+
+	tests := []struct {
+		query    string
+		code     string
+		expected []string
+	}{
+		{"PREPARE ins AS INSERT INTO implicittxtest (k, v) VALUES ($1, $2); EXECUTE ins(5, 5); EXECUTE ins(6, 6)", "", []string{"BEGIN", "COMMIT"}},
+		{"EXECUTE ins(7, 7); SELECT * FROM nosuchtable; EXECUTE ins(8, 8)", "42P01", []string{"BEGIN", "ROLLBACK"}},
+		{"EXECUTE ins(9, 9); SELEKT 1", "42601", []string{"BEGIN", "ROLLBACK"}},
+		{"EXECUTE ins(10, 10)", "", []string{}},
+		{"BEGIN ISOLATION LEVEL SERIALIZABLE; EXECUTE ins(11, 11); COMMIT", "", []string{"BEGIN", "COMMIT"}},
+	}
+
+	for _, test := range tests {
+		executor.commands = []string{}
+		_, err := conn.Exec(context.Background(), test.query).ReadAll()
+		if len(test.code) == 0 {
+			if err != nil {
+				t.Errorf("%s: %s", test.query, err)
+			}
+		} else if !hasSQLState(err, test.code) {
+			t.Errorf("%s: %v (expected %s)", test.query, err, test.code)
+		}
+		if fmt.Sprintf("%v", executor.commands) != fmt.Sprintf("%v", test.expected) {
+			t.Errorf("%s: %v != %v", test.query, executor.commands, test.expected)
+		}
+		if conn.TxStatus() != 'I' {
+			t.Errorf("%s: %c != I", test.query, conn.TxStatus())
+		}
+	}
+}
+
+// txControlTestExecutor represents a query executor which records the transaction control statements.
+type txControlTestExecutor struct {
+	postgresql.QueryExecutor
+	commands []string
+}
+
+// Begin records the BEGIN statement.
+func (executor *txControlTestExecutor) Begin(conn postgresql.Conn, stmt query.Begin) (protocol.Responses, error) {
+	executor.commands = append(executor.commands, "BEGIN")
+	return executor.QueryExecutor.Begin(conn, stmt)
+}
+
+// Commit records the COMMIT statement.
+func (executor *txControlTestExecutor) Commit(conn postgresql.Conn, stmt query.Commit) (protocol.Responses, error) {
+	executor.commands = append(executor.commands, "COMMIT")
+	return executor.QueryExecutor.Commit(conn, stmt)
+}
+
+// Rollback records the ROLLBACK statement.
+func (executor *txControlTestExecutor) Rollback(conn postgresql.Conn, stmt query.Rollback) (protocol.Responses, error) {
+	executor.commands = append(executor.commands, "ROLLBACK")
+	return executor.QueryExecutor.Rollback(conn, stmt)
+}
+
+// RunSavepointTest tests that the savepoints recover the aborted transaction blocks.
+func RunSavepointTest(t *testing.T, server *Server, testDBName string) {
+	t.Helper()
+
+	conn, ok := connectTestDatabase(t, testDBName,
+		"CREATE TABLE savepointtest (k INTEGER PRIMARY KEY, v INTEGER)",
+	)
+	if !ok {
+		return
+	}
+	defer conn.Close(context.Background())
+
+	runTxStatusTests(t, conn, []txStatusTest{
+		{"SAVEPOINT sp1", "25P01", 'I'},
+		{"BEGIN; SAVEPOINT sp1", "", 'T'},
+		{"INSERT INTO savepointtest (k, v) VALUES (1, 1)", "", 'T'},
+		{"SELECT * FROM nosuchtable", "42P01", 'E'},
+		{"SELECT * FROM savepointtest", "25P02", 'E'},
+		{"SAVEPOINT sp2", "25P02", 'E'},
+		{"ROLLBACK TO SAVEPOINT nosuchsavepoint", "3B001", 'E'},
+		{"ROLLBACK TO SAVEPOINT sp1", "", 'T'},
+		{"SELECT * FROM savepointtest", "", 'T'},
+		{"SAVEPOINT sp2; RELEASE SAVEPOINT sp1", "", 'T'},
+		{"RELEASE sp2", "3B001", 'E'},
+		{"ROLLBACK TO sp1", "3B001", 'E'},
+		{"ROLLBACK", "", 'I'},
+	})
+
+	// Nested transactions of pgx use the savepoints with the extended query protocol.
+
+	pgxConn, err := pgx.Connect(context.Background(), testDatabaseURL(testDBName))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer pgxConn.Close(context.Background())
+
+	tx, err := pgxConn.Begin(context.Background())
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	nestedTx, err := tx.Begin(context.Background())
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	_, err = nestedTx.Exec(context.Background(), "SELECT * FROM nosuchtable")
+	if err == nil {
+		t.Errorf("error is not returned")
+	}
+	err = nestedTx.Rollback(context.Background())
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	_, err = tx.Exec(context.Background(), "INSERT INTO savepointtest (k, v) VALUES ($1, $2)", 2, 2)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	err = tx.Commit(context.Background())
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+// RunTransactionModeTest tests that the statements which modify data are rejected in read-only transactions.
+func RunTransactionModeTest(t *testing.T, server *Server, testDBName string) {
+	t.Helper()
+
+	conn, ok := connectTestDatabase(t, testDBName,
+		"CREATE TABLE txmodetest (k INTEGER PRIMARY KEY, v INTEGER)",
+	)
+	if !ok {
+		return
+	}
+	defer conn.Close(context.Background())
+
+	runTxStatusTests(t, conn, []txStatusTest{
+		{"BEGIN ISOLATION LEVEL SERIALIZABLE, READ ONLY", "", 'T'},
+		{"SELECT * FROM txmodetest", "", 'T'},
+		{"INSERT INTO txmodetest (k, v) VALUES (1, 1)", "25006", 'E'},
+		{"ROLLBACK", "", 'I'},
+		{"START TRANSACTION; SET TRANSACTION READ ONLY", "", 'T'},
+		{"DELETE FROM txmodetest", "25006", 'E'},
+		{"ROLLBACK", "", 'I'},
+		{"BEGIN READ ONLY; SET TRANSACTION READ WRITE", "", 'T'},
+		{"INSERT INTO txmodetest (k, v) VALUES (1, 1)", "", 'T'},
+		{"COMMIT", "", 'I'},
+		{"BEGIN READ ONLY; ROLLBACK; INSERT INTO txmodetest (k, v) VALUES (2, 2)", "", 'I'},
+		{"SET TRANSACTION READ ONLY; INSERT INTO txmodetest (k, v) VALUES (3, 3)", "", 'I'},
+		{"BEGIN ISOLATION LEVEL", "42601", 'I'},
+	})
+
+	// The transaction options of pgx are rejected with the extended query protocol too.
+
+	pgxConn, err := pgx.Connect(context.Background(), testDatabaseURL(testDBName))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer pgxConn.Close(context.Background())
+
+	tx, err := pgxConn.BeginTx(context.Background(), pgx.TxOptions{IsoLevel: pgx.Serializable, AccessMode: pgx.ReadOnly, DeferrableMode: pgx.Deferrable})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	_, err = tx.Exec(context.Background(), "UPDATE txmodetest SET v = $1 WHERE k = $2", 10, 1)
+	if !hasSQLState(err, "25006") {
+		t.Errorf("%v (expected 25006)", err)
+	}
+	err = tx.Rollback(context.Background())
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+// RunTwoPhaseCommitTest tests that the prepared transactions survive the connection close until they are finished.
+func RunTwoPhaseCommitTest(t *testing.T, server *Server, testDBName string) {
+	t.Helper()
+
+	conn, ok := connectTestDatabase(t, testDBName,
+		"CREATE TABLE twophasetest (k INTEGER PRIMARY KEY, v INTEGER)",
+	)
+	if !ok {
+		return
+	}
+
+	runCommandTagTests(t, conn, []commandTagTest{
+		{"BEGIN; INSERT INTO twophasetest (k, v) VALUES (1, 1); PREPARE TRANSACTION 'gx1'", "", []string{"BEGIN", "INSERT 0 1", "PREPARE TRANSACTION"}},
+		{"BEGIN; PREPARE TRANSACTION 'gx2'", "", []string{"BEGIN", "PREPARE TRANSACTION"}},
+	})
+
+	// The transaction which cannot be prepared is rolled back.
+
+	executor := &txControlTestExecutor{
+		QueryExecutor: server.QueryExecutor(),
+		commands:      []string{},
+	}
+	server.SetQueryExecutor(executor)
+	_, err := conn.Exec(context.Background(), "BEGIN; PREPARE TRANSACTION 'gx1'").ReadAll()
+	server.SetQueryExecutor(executor.QueryExecutor)
+	if !hasSQLState(err, "42710") {
+		t.Errorf("%v (expected 42710)", err)
+	}
+	if fmt.Sprintf("%v", executor.commands) != "[BEGIN ROLLBACK]" {
+		t.Errorf("%v != [BEGIN ROLLBACK]", executor.commands)
+	}
+	if conn.TxStatus() != 'I' {
+		t.Errorf("%c != I", conn.TxStatus())
+	}
+
+	runCommandTagTests(t, conn, []commandTagTest{
+		{"BEGIN; COMMIT PREPARED 'gx1'", "25001", nil},
+		{"ROLLBACK", "", []string{"ROLLBACK"}},
+	})
+	if conn.TxStatus() != 'I' {
+		t.Errorf("%c != I", conn.TxStatus())
+	}
+
+	conn.Close(context.Background())
+
+	// The prepared transactions are finished by another connection.
+
+	conn, ok = connectTestDatabase(t, testDBName)
+	if !ok {
+		return
+	}
+	defer conn.Close(context.Background())
+
+	results, err := conn.Exec(context.Background(), "SELECT gid, database FROM pg_prepared_xacts").ReadAll()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(results) != 1 || len(results[0].Rows) != 2 {
+		t.Errorf("rows of pg_prepared_xacts != 2")
+	} else {
+		for n, gid := range []string{"gx1", "gx2"} {
+			row := results[0].Rows[n]
+			if string(row[0]) != gid || string(row[1]) != testDBName {
+				t.Errorf("%s %s != %s %s", row[0], row[1], gid, testDBName)
+			}
+		}
+	}
+
+	runCommandTagTests(t, conn, []commandTagTest{
+		{"COMMIT PREPARED 'gx1'", "", []string{"COMMIT PREPARED"}},
+		{"COMMIT PREPARED 'gx1'", "42704", nil},
+		{"ROLLBACK PREPARED 'gx2'", "", []string{"ROLLBACK PREPARED"}},
+		{"SELECT * FROM pg_catalog.pg_prepared_xacts", "", []string{"SELECT 0"}},
+	})
+}